@@ -0,0 +1,121 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// cacheEntry is the cached promise for a key
+type cacheEntry struct {
+	promise Promise
+	pending bool
+	expires time.Time
+}
+
+// promiseCache caches successful promises by key for a ttl
+type promiseCache struct {
+	lock    sync.RWMutex
+	ttl     time.Duration
+	entries map[string]*cacheEntry
+}
+
+// Cached wraps factory so that a successful promise is returned by each
+// call for ttl after it is delivered
+//
+//	Notes
+//		While the promise from factory is pending, every call shares it.
+//		Once it succeeds, it is returned until ttl expires, and the next
+//		call after that invokes factory again. A failed promise is not
+//		cached, so the next call invokes factory again
+//
+//		Expired promises are removed whenever factory is invoked
+//
+func Cached(ttl time.Duration, factory Factory) Factory {
+	return CachedWithKey(ttl, func() string { return "" }, factory)
+}
+
+// CachedWithKey wraps factory so that a successful promise is cached for
+// ttl, per key returned by keyFn
+//
+//	Notes
+//		keyFn is invoked for each call to determine the cache key. See
+//		Cached
+//
+func CachedWithKey(ttl time.Duration, keyFn func() string, factory Factory) Factory {
+	c := &promiseCache{ttl: ttl, entries: make(map[string]*cacheEntry)}
+
+	return func() Promise {
+		return c.get(keyFn(), factory)
+	}
+}
+
+// lookup returns the cached promise for key, if it is pending or has not
+// expired
+//
+//	Notes
+//		The caller must hold c.lock (read or write)
+//
+func (c *promiseCache) lookup(key string) (Promise, bool) {
+	if entry, ok := c.entries[key]; ok && (entry.pending || time.Now().Before(entry.expires)) {
+		return entry.promise, true
+	}
+
+	return nil, false
+}
+
+// sweep removes the expired entries, so the entries of keys that are not
+// requested again do not accumulate
+//
+//	Notes
+//		The caller must hold c.lock for writing
+//
+func (c *promiseCache) sweep() {
+	now := time.Now()
+
+	for key, entry := range c.entries {
+		if !entry.pending && !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// get returns the cached promise for key, or invokes factory and caches its
+// promise
+func (c *promiseCache) get(key string, factory Factory) Promise {
+	c.lock.RLock()
+	p, ok := c.lookup(key)
+	c.lock.RUnlock()
+
+	if ok {
+		return p
+	}
+
+	c.lock.Lock()
+
+	// another caller may have invoked factory while we were unlocked
+	if p, ok := c.lookup(key); ok {
+		c.lock.Unlock()
+		return p
+	}
+
+	c.sweep()
+
+	entry := &cacheEntry{promise: factory(), pending: true}
+	c.entries[key] = entry
+
+	alwaysAfterUnlock(&c.lock, entry.promise, func(p Controller) {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		// the TTL starts when the promise succeeds
+		entry.pending = false
+		entry.expires = time.Now().Add(c.ttl)
+
+		// only remove the entry if it is still the cached entry for key
+		if !p.IsSuccess() && c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+	})
+
+	return entry.promise
+}
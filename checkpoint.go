@@ -0,0 +1,42 @@
+package promise
+
+import "sync/atomic"
+
+// CheckpointHook is the function prototype for checkpoint callbacks
+type CheckpointHook func(name string, p Controller)
+
+// globalCheckpointHook is invoked for every checkpoint (see
+// GlobalCheckpointHook)
+var globalCheckpointHook atomic.Pointer[CheckpointHook]
+
+// GlobalCheckpointHook sets a hook that is invoked for every checkpoint in
+// the process, in addition to the fn of each checkpoint
+//
+//	Notes
+//		GlobalCheckpointHook(nil) removes the hook
+//
+func GlobalCheckpointHook(fn func(name string, p Controller)) {
+	if fn == nil {
+		globalCheckpointHook.Store(nil)
+		return
+	}
+
+	hook := CheckpointHook(fn)
+	globalCheckpointHook.Store(&hook)
+}
+
+// Checkpoint registers a named checkpoint that invokes fn when the promise
+// is delivered
+func (p *promise) Checkpoint(name string, fn func(name string, p Controller)) Promise {
+	p.Always(func(p2 Controller) {
+		if hook := globalCheckpointHook.Load(); hook != nil {
+			(*hook)(name, p2)
+		}
+
+		if fn != nil {
+			fn(name, p2)
+		}
+	})
+
+	return p
+}
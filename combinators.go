@@ -0,0 +1,876 @@
+package promise
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoResult is used as the error result when none of a list of promises
+// delivered a usable result
+var ErrNoResult = fmt.Errorf("No promise delivered a result")
+
+// ErrNoMatchingCase is used as the error result by ThenSwitchAll when there
+// is no factory for the selected key
+var ErrNoMatchingCase = fmt.Errorf("No case matches the selected key")
+
+// ErrResultNotSlice is used as the error result when a combinator requires
+// a []interface{} result, and the result is some other type
+var ErrResultNotSlice = fmt.Errorf("Promise result is not a []interface{}")
+
+// Coalesce returns a promise for the first promise in the list that succeeds
+// with a non-nil result
+//
+//	Notes
+//		Like SQL's COALESCE, the promises are considered in order, so the
+//		result of promises[0] is always preferred over promises[1], etc.
+//
+//		A successful delivery of nil is treated as "no value" and the next
+//		promise is considered. If no promise delivers a non-nil result, the
+//		returned promise fails with the error from the last failed promise,
+//		or ErrNoResult if none of the promises failed
+//
+func Coalesce(promises ...Promise) Promise {
+	result := newPromise()
+
+	coalesce(result, promises, nil)
+
+	return result
+}
+
+// coalesce delivers result with the first non-nil success from promises
+func coalesce(result Controller, promises []Promise, err error) {
+	if len(promises) == 0 {
+		if err == nil {
+			err = ErrNoResult
+		}
+
+		result.Fail(err)
+		return
+	}
+
+	promises[0].Always(func(p Controller) {
+		if p.IsSuccess() && p.Result() != nil {
+			result.DeliverWithPromise(p)
+			return
+		}
+
+		// remember the most recent failure for the case where nothing succeeds
+		if p.IsFailed() {
+			err = p.Error()
+		}
+
+		coalesce(result, promises[1:], err)
+	})
+}
+
+// All returns a promise that succeeds when all of the promises succeed
+//
+//	Notes
+//		The returned promise fails on the first failure. If there are no
+//		promises, the returned promise is already successful
+//
+//		All is the equivalent of ThenAll without a promise to chain to
+//
+func All(promises ...Promise) Promise {
+	return all(promises)
+}
+
+// Zip returns a promise that succeeds with the results of a and b when both
+// succeed
+//
+//	Notes
+//		The result of the returned promise is a [2]interface{} holding the
+//		result of a and the result of b, in that order. The returned promise
+//		fails on the first failure
+//
+func Zip(a, b Promise) Promise {
+	return allWithResults([]Promise{a, b}, false).ThenWithResult(func(result interface{}) Promise {
+		results := result.([]interface{})
+
+		return newPromise().SucceedWithResult([2]interface{}{results[0], results[1]})
+	})
+}
+
+// ZipAll returns a promise that succeeds with the results of the promises
+// when all of them succeed
+//
+//	Notes
+//		The result of the returned promise is a []interface{} of the
+//		results, in the same order as promises (regardless of the order in
+//		which they are delivered). The returned promise fails on the first
+//		failure. If there are no promises, it succeeds with an empty slice
+//
+//		ZipAll is the equivalent of ThenAllWithResults without a promise to
+//		chain to
+//
+func ZipAll(promises ...Promise) Promise {
+	return allWithResults(promises, false)
+}
+
+// Any returns a promise for the first of the promises to succeed
+//
+//	Notes
+//		Failures are ignored until all of the promises have failed, and then
+//		the returned promise fails with the error of the last failure. This
+//		suits a best-effort fallback, where any one source is good enough.
+//		To deliver the first result regardless of outcome, use Race
+//
+//		If there are no promises, the returned promise is already successful
+//
+func Any(promises ...Promise) Promise {
+	return firstSuccess(promises, false)
+}
+
+// Race returns a promise that is delivered with the result of the first of
+// the promises to be delivered
+//
+//	Notes
+//		This is the equivalent of Promise.race for JavaScript. The first
+//		delivery wins, whether it is a success or a failure, and later
+//		deliveries are ignored
+//
+//		If there are no promises, the returned promise is never delivered
+//
+func Race(promises ...Promise) Promise {
+	result := newPromise()
+
+	for _, promise := range promises {
+		promise.Always(func(p Controller) {
+			// use tryDeliver as every promise after the first is a loser
+			result.tryDeliver(p.RawResult())
+		})
+
+		// early-out in case the promise got delivered synchronously
+		if result.IsDelivered() {
+			break
+		}
+	}
+
+	return result
+}
+
+// settle returns a promise that succeeds once all of the promises have been
+// delivered, regardless of the outcome of each delivery
+func settle(promises []Promise) Promise {
+	// how many promises must be delivered?
+	count := int64(len(promises))
+
+	// none? return success
+	if count == 0 {
+		return resolved
+	}
+
+	result := newPromise()
+
+	for _, promise := range promises {
+		promise.Always(func(Controller) {
+			if atomic.AddInt64(&count, -1) == 0 {
+				result.Succeed()
+			}
+		})
+	}
+
+	return result
+}
+
+// settledControllers returns a promise that succeeds once all of the
+// promises have been delivered, with the Controller passed to the Always
+// handler of each promise (in the same order as promises)
+func settledControllers(promises []Promise) Promise {
+	result := newPromise()
+	controllers := make([]Controller, len(promises))
+
+	// how many promises must be delivered?
+	count := int64(len(promises))
+
+	// none? succeed with no controllers
+	if count == 0 {
+		return result.SucceedWithResult(controllers)
+	}
+
+	for i, promise := range promises {
+		promise.Always(func(p Controller) {
+			controllers[i] = p
+
+			// the atomic decrement orders the writes to controllers
+			if atomic.AddInt64(&count, -1) == 0 {
+				result.SucceedWithResult(controllers)
+			}
+		})
+	}
+
+	return result
+}
+
+// lazyAll invokes the factories with at most maxConcurrent promises
+// in-flight, and collects the results in the same order as factories
+func lazyAll(maxConcurrent int, factories []Factory) Promise {
+	if len(factories) == 0 {
+		return newPromise().SucceedWithResult([]interface{}{})
+	}
+
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	result := newPromise()
+	results := make([]interface{}, len(factories))
+
+	var lock sync.Mutex
+	var next int
+	remaining := len(factories)
+
+	var start func()
+	start = func() {
+		lock.Lock()
+
+		// nothing left to start, or we already failed?
+		if next == len(factories) || result.IsDelivered() {
+			lock.Unlock()
+			return
+		}
+
+		i := next
+		next++
+
+		lock.Unlock()
+
+		factories[i]().Always(func(p Controller) {
+			if p.IsFailed() {
+				// use tryDeliver as other in-flight promises may also fail
+				result.tryDeliver(p.Error())
+				return
+			}
+
+			lock.Lock()
+			results[i] = p.Result()
+			remaining--
+			done := remaining == 0
+			lock.Unlock()
+
+			if done {
+				result.SucceedWithResult(results)
+			} else {
+				start()
+			}
+		})
+	}
+
+	for i := 0; i < maxConcurrent; i++ {
+		start()
+	}
+
+	return result
+}
+
+// firstSuccess returns a promise for the first of the promises to succeed
+//
+//	Notes
+//		The returned promise fails only when all of the promises have
+//		failed. If multiError is true, it fails with a *MultiError of the
+//		failures in order of delivery, and with ErrNotEnoughPromises if
+//		there are no promises. Otherwise it fails with the error of the last
+//		failure, and succeeds if there are no promises
+//
+func firstSuccess(promises []Promise, multiError bool) Promise {
+	// how many promises must fail?
+	count := len(promises)
+
+	// none? return success, unless a success is required
+	if count == 0 {
+		if multiError {
+			return newPromise().Fail(ErrNotEnoughPromises)
+		}
+
+		return resolved
+	}
+
+	result := newPromise()
+
+	var lock sync.Mutex
+	var errs []error
+
+	for _, promise := range promises {
+		promise.Always(func(p Controller) {
+			if p.IsSuccess() {
+				// use tryDeliver as more than one promise may succeed
+				result.tryDeliver(p.RawResult())
+				return
+			}
+
+			lock.Lock()
+			errs = append(errs, p.Error())
+			failed := len(errs) == count
+			lock.Unlock()
+
+			if !failed {
+				return
+			}
+
+			if multiError {
+				result.tryDeliver(&MultiError{Errors: errs})
+			} else {
+				result.tryDeliver(p.Error())
+			}
+		})
+	}
+
+	return result
+}
+
+// ordered returns a promise that succeeds when all of the promises succeed,
+// considering each promise only after the previous one has succeeded
+func ordered(promises []Promise) Promise {
+	// none? return success
+	if len(promises) == 0 {
+		return resolved
+	}
+
+	result := newPromise()
+
+	var next func(i int)
+	next = func(i int) {
+		promises[i].Always(func(p Controller) {
+			// deliver on the first failure, or the success of the last promise
+			if p.IsFailed() || i == len(promises)-1 {
+				result.DeliverWithPromise(p)
+			} else {
+				next(i + 1)
+			}
+		})
+	}
+
+	next(0)
+
+	return result
+}
+
+// SlidingWindow batches the delivery of a list of promises into windows of
+// n promises
+//
+//	Notes
+//		A placeholder promise is returned for each promise in the list. The
+//		placeholders in a window are delivered with the result of their
+//		promise, but only once every placeholder in the previous window has
+//		been delivered, so windows are processed sequentially while the
+//		promises within a window are processed concurrently
+//
+//		If n <= 0, all of the promises are in a single window
+//
+func SlidingWindow(n int, promises []Promise) []Promise {
+	if n <= 0 {
+		n = len(promises)
+	}
+
+	result := make([]Promise, len(promises))
+
+	// the first window can start immediately
+	var gate Promise = resolved
+
+	for start := 0; start < len(promises); start += n {
+		end := start + n
+		if end > len(promises) {
+			end = len(promises)
+		}
+
+		window := result[start:end]
+
+		for i := start; i < end; i++ {
+			placeholder := newPromise()
+			promise := promises[i]
+
+			// once the window can start, deliver with the promise result
+			gate.Always(func(Controller) {
+				promise.Always(func(p Controller) {
+					placeholder.DeliverWithPromise(p)
+				})
+			})
+
+			result[i] = placeholder
+		}
+
+		// the next window starts once this window is delivered
+		gate = settle(window)
+	}
+
+	return result
+}
+
+// MapN combines the results of a list of promises using a transform
+//
+//	Notes
+//		fn is invoked with the results of the promises, in the same order as
+//		promises, once all of them have succeeded. The returned promise
+//		fails on the first failure of a promise, or with the error from fn
+//
+//		The results are collected directly as each promise is delivered,
+//		rather than via ThenAll, so there is no intermediate promise
+//
+func MapN(promises []Promise, fn func(results []interface{}) (interface{}, error)) Promise {
+	result := newPromise()
+	results := make([]interface{}, len(promises))
+
+	// how many promises must succeed?
+	count := int64(len(promises))
+
+	transform := func() {
+		value, err := fn(results)
+		if err != nil {
+			result.Fail(err)
+		} else {
+			result.SucceedWithResult(value)
+		}
+	}
+
+	// none? transform the empty list
+	if count == 0 {
+		transform()
+		return result
+	}
+
+	for i, promise := range promises {
+		promise.Always(func(p Controller) {
+			if !p.IsSuccess() {
+				// use tryDeliver as more than one promise may fail
+				result.tryDeliver(p.Error())
+				return
+			}
+
+			results[i] = p.Result()
+
+			// the atomic decrement orders the writes to results
+			if atomic.AddInt64(&count, -1) == 0 {
+				transform()
+			}
+		})
+	}
+
+	return result
+}
+
+// allWithResults returns a promise that succeeds with the results of all of
+// the promises, in the same order as promises, or fails on the first failure
+//
+//	Notes
+//		If cancelOnFail is true, the promises that are still pending when
+//		a promise fails are canceled
+//
+func allWithResults(promises []Promise, cancelOnFail bool) Promise {
+	result := newPromise()
+	results := make([]interface{}, len(promises))
+
+	// how many promises must succeed?
+	count := int64(len(promises))
+
+	// none? succeed with no results
+	if count == 0 {
+		return result.SucceedWithResult(results)
+	}
+
+	for i, promise := range promises {
+		promise.Always(func(p Controller) {
+			if !p.IsSuccess() {
+				// use tryDeliver as more than one promise may fail, and only
+				// the first failure cancels the remaining promises
+				if result.tryDeliver(p.Error()) && cancelOnFail {
+					for _, sibling := range promises {
+						cancelPending(sibling)
+					}
+				}
+
+				return
+			}
+
+			results[i] = p.Result()
+
+			// the atomic decrement orders the writes to results
+			if atomic.AddInt64(&count, -1) == 0 {
+				result.SucceedWithResult(results)
+			}
+		})
+	}
+
+	return result
+}
+
+// cancelPending cancels p if it is a Controller that is still pending
+//
+//	Notes
+//		For the promises in this package, the pending check and the cancel
+//		are atomic, so a promise delivered concurrently is not logged as a
+//		double delivery
+//
+func cancelPending(p Promise) {
+	switch p := p.(type) {
+	case *promise:
+		p.tryDeliver(ErrPromiseCanceled)
+	case Controller:
+		if p.IsPending() {
+			p.Cancel()
+		}
+	}
+}
+
+// Latch returns a function that returns a promise reflecting the delivery
+// of p
+//
+//	Notes
+//		Every call returns the same promise, which is pending while p is
+//		pending, and delivered with the result of p once p is delivered.
+//		The returned promise is separate from p, so late callers can share
+//		the delivery of p without being handed the Controller for p
+//
+func Latch(p Promise) func() Promise {
+	latch := newPromise()
+
+	p.Always(func(p2 Controller) {
+		latch.DeliverWithPromise(p2)
+	})
+
+	return func() Promise {
+		return latch
+	}
+}
+
+// Stabilize returns a promise that succeeds when at least quorum of the
+// promises have succeeded, and none of the promises fail for window after
+// the quorum is reached
+//
+//	Notes
+//		If successful, the result of the returned promise is a []interface{}
+//		of the results that formed the quorum, in order of delivery. Promises
+//		that succeed within the window are not included
+//
+//		A failure after the quorum is reached, but before the window
+//		elapses, resets the quorum count, so the quorum must then be reached
+//		by promises that have not yet been delivered. A failure before the
+//		quorum is reached does not reset the count. The returned promise
+//		fails, with the error of the last failure, once the quorum can no
+//		longer be reached
+//
+func Stabilize(quorum int, window time.Duration, promises []Promise) Promise {
+	result := newPromise()
+
+	if quorum <= 0 {
+		return result.SucceedWithResult([]interface{}{})
+	}
+
+	var lock sync.Mutex
+	var successes []interface{}
+	var timer *time.Timer
+	var generation int
+	pending := len(promises)
+
+	// quorum can never be reached?
+	if pending < quorum {
+		return result.Fail(ErrNoResult)
+	}
+
+	for _, promise := range promises {
+		promise.Always(func(p Controller) {
+			lock.Lock()
+
+			pending--
+
+			if p.IsSuccess() {
+				successes = append(successes, p.Result())
+
+				// start the stability window once the quorum is reached, with
+				// the results frozen, so later successes are not included
+				if len(successes) >= quorum && timer == nil {
+					current := generation
+					results := append([]interface{}(nil), successes...)
+
+					timer = time.AfterFunc(window, func() {
+						lock.Lock()
+						stable := current == generation
+						lock.Unlock()
+
+						// the window was reset by a failure?
+						if stable {
+							result.tryDeliver(results)
+						}
+					})
+				}
+
+				lock.Unlock()
+				return
+			}
+
+			// a failure within the stability window resets the quorum
+			if timer != nil {
+				generation++
+				successes = nil
+
+				timer.Stop()
+				timer = nil
+			}
+
+			failed := len(successes)+pending < quorum
+
+			lock.Unlock()
+
+			// deliver without holding the lock, as the handlers of result
+			// may deliver another one of the promises
+			if failed {
+				result.tryDeliver(p.Error())
+			}
+		})
+	}
+
+	return result
+}
+
+// Interrupt returns a promise that is delivered with the result of p, unless
+// one of the signals is delivered first
+//
+//	Notes
+//		When a signal is delivered first (whether it succeeds or fails) the
+//		returned promise is canceled, and p is canceled if it is a Controller
+//		that is still pending. Unlike a race, the delivery of a signal never
+//		becomes the result of the returned promise
+//
+func Interrupt(p Promise, signals ...Promise) Promise {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		result.tryDeliver(p2.RawResult())
+	})
+
+	for _, signal := range signals {
+		signal.Always(func(Controller) {
+			if result.tryDeliver(ErrPromiseCanceled) {
+				cancelPending(p)
+			}
+		})
+	}
+
+	return result
+}
+
+// Wrap returns a new Controller that is delivered with the result of p
+//
+//	Notes
+//		This bridges a Promise (for example, from another package) to a
+//		Controller, so the delivery can be passed to DeliverWithPromise, or
+//		anything else that requires a Controller. Delivering the returned
+//		Controller does not deliver p, so if it is delivered (or canceled)
+//		before p, the later delivery of p is ignored
+//
+func Wrap(p Promise) Controller {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		result.tryDeliver(p2.RawResult())
+	})
+
+	return result
+}
+
+// allWithResultsMap returns a promise that succeeds with the results of
+// all of the promises, by key, or fails on the first failure
+func allWithResultsMap(promises map[string]Promise) Promise {
+	keys := make([]string, 0, len(promises))
+	list := make([]Promise, 0, len(promises))
+
+	for key, promise := range promises {
+		keys = append(keys, key)
+		list = append(list, promise)
+	}
+
+	return allWithResults(list, false).ThenWithResult(func(result interface{}) Promise {
+		results := make(map[string]interface{}, len(keys))
+
+		for i, r := range result.([]interface{}) {
+			results[keys[i]] = r
+		}
+
+		return newPromise().SucceedWithResult(results)
+	})
+}
+
+// Flatten returns a promise for the result of p, where a result that is
+// itself a Promise is unwrapped by one level
+//
+//	Notes
+//		See FlattenN
+//
+func Flatten(p Promise) Promise {
+	return FlattenN(1, p)
+}
+
+// Flatten2 returns a promise for the result of p, where a result that is
+// itself a Promise is unwrapped by up to two levels
+//
+//	Notes
+//		See FlattenN
+//
+func Flatten2(p Promise) Promise {
+	return FlattenN(2, p)
+}
+
+// FlattenN returns a promise for the result of p, where a result that is
+// itself a Promise is unwrapped by up to n levels
+//
+//	Notes
+//		When p succeeds with a Promise, the returned promise is delivered
+//		with the result of that promise, and so on, up to n times. If n is
+//		-1, promises are unwrapped until the result is not a Promise
+//
+//		A failure at any level fails the returned promise
+//
+func FlattenN(n int, p Promise) Promise {
+	result := newPromise()
+
+	flatten(result, n, p)
+
+	return result
+}
+
+// flatten delivers result with the result of p, unwrapping n levels
+func flatten(result *promise, n int, p Promise) {
+	p.Always(func(p2 Controller) {
+		if inner, ok := p2.RawResult().(Promise); ok && n != 0 {
+			flatten(result, n-1, inner)
+		} else {
+			result.tryDeliver(p2.RawResult())
+		}
+	})
+}
+
+// WhenN returns a promise that succeeds once n of the promises have
+// succeeded
+//
+//	Notes
+//		If successful, the result of the returned promise is a []interface{}
+//		of the first n results, in order of delivery. n == 1 succeeds with
+//		the first success, and n == len(promises) requires all of the
+//		promises to succeed
+//
+//		Once n successes are no longer possible, the returned promise fails
+//		with a *MultiError of the failures. If n > len(promises), it fails
+//		with ErrNotEnoughPromises
+//
+func WhenN(n int, promises ...Promise) Promise {
+	result := newPromise()
+
+	if n <= 0 {
+		return result.SucceedWithResult([]interface{}{})
+	}
+
+	if n > len(promises) {
+		return result.Fail(ErrNotEnoughPromises)
+	}
+
+	var lock sync.Mutex
+	var done bool
+	var results []interface{}
+	var errs []error
+
+	for _, promise := range promises {
+		promise.Always(func(p Controller) {
+			var delivery interface{}
+
+			lock.Lock()
+
+			if !done {
+				if p.IsSuccess() {
+					results = append(results, p.Result())
+
+					if len(results) == n {
+						delivery, done = results, true
+					}
+				} else {
+					errs = append(errs, p.Error())
+
+					// can n successes still be reached?
+					if len(promises)-len(errs) < n {
+						delivery, done = &MultiError{Errors: errs}, true
+					}
+				}
+			}
+
+			lock.Unlock()
+
+			// deliver without holding the lock, as the handlers of result
+			// may deliver another one of the promises
+			if delivery != nil {
+				result.tryDeliver(delivery)
+			}
+		})
+	}
+
+	return result
+}
+
+// Aggregate returns a promise that folds the results of the promises into
+// initial, once all of the promises have been delivered
+//
+//	Notes
+//		fn is invoked with the accumulated value and the result of each
+//		successful promise, in the same order as promises, and the result
+//		of the returned promise is the final accumulated value
+//
+//		If any of the promises failed, errFn is invoked with a MultiError of
+//		the failures (in the same order as promises). If errFn returns nil,
+//		the partial failures are accepted and the returned promise succeeds.
+//		Otherwise it fails with the error from errFn. A nil errFn fails the
+//		returned promise with the *MultiError
+//
+func Aggregate(promises []Promise, initial interface{}, fn func(acc, result interface{}) interface{}, errFn func(MultiError) error) Promise {
+	return settledControllers(promises).ThenWithResult(func(result interface{}) Promise {
+		acc := initial
+		var errs []error
+
+		for _, p := range result.([]Controller) {
+			if p.IsSuccess() {
+				acc = fn(acc, p.Result())
+			} else {
+				errs = append(errs, p.Error())
+			}
+		}
+
+		if len(errs) > 0 {
+			var err error = &MultiError{Errors: errs}
+			if errFn != nil {
+				err = errFn(MultiError{Errors: errs})
+			}
+
+			if err != nil {
+				return newPromise().Fail(err)
+			}
+		}
+
+		return newPromise().SucceedWithResult(acc)
+	})
+}
+
+// Shared returns a promise for the delivery of p that can be consumed by
+// multiple independent chains
+//
+//	Notes
+//		Every handler registered on a promise receives the same delivery,
+//		and each Then* call creates a new promise, so consumers of the
+//		returned promise do not affect each other's chains
+//
+//		The returned promise is separate from p (see Wrap), so a consumer
+//		that delivers (or cancels) it does not deliver p, and does not
+//		affect consumers of p itself
+//
+func Shared(p Promise) Promise {
+	return Wrap(p)
+}
+
+// SucceedOnFirst returns a promise that succeeds with the result of the
+// first of the promises to succeed, even if the result is nil
+//
+//	Notes
+//		The returned promise fails only when all of the promises have
+//		failed, with a *MultiError of the failures in order of delivery. If
+//		there are no promises, it fails with ErrNotEnoughPromises
+//
+func SucceedOnFirst(promises ...Promise) Promise {
+	return firstSuccess(promises, true)
+}
@@ -0,0 +1,34 @@
+package promise
+
+import "reflect"
+
+// EqualResult determines if two promises have been delivered with the same
+// outcome
+//
+//	Notes
+//		Returns true if both promises succeeded with results that are equal
+//		via reflect.DeepEqual, both failed with equal errors (also via
+//		reflect.DeepEqual), or both were canceled. Returns false if either
+//		promise is still pending
+//
+func EqualResult(a, b Promise) bool {
+	ac, ok := a.(Controller)
+	if !ok {
+		return false
+	}
+
+	bc, ok := b.(Controller)
+	if !ok {
+		return false
+	}
+
+	if ac.IsPending() || bc.IsPending() {
+		return false
+	}
+
+	if ac.IsSuccess() != bc.IsSuccess() || ac.IsCanceled() != bc.IsCanceled() {
+		return false
+	}
+
+	return reflect.DeepEqual(ac.RawResult(), bc.RawResult())
+}
@@ -0,0 +1,39 @@
+// Package context integrates promises with context.Context
+//
+//	Notes
+//		The integration lives in its own package so that all of the context
+//		bridging utilities are in one place
+//
+package context
+
+import (
+	stdcontext "context"
+
+	promise "github.com/gotomgo/go-promises"
+)
+
+// FromContext creates a promise that is canceled when ctx is done
+//
+//	Notes
+//		If ctx is already done, the promise is returned already canceled.
+//		Otherwise no goroutine is used to monitor ctx, and the monitoring
+//		stops when the promise is delivered
+//
+//		FromContext is equivalent to promise.NewPromiseWithContext
+//
+func FromContext(ctx stdcontext.Context) promise.Controller {
+	return promise.NewPromiseWithContext(ctx)
+}
+
+// IntoContext returns a copy of ctx that carries p as the value for key
+func IntoContext(ctx stdcontext.Context, key interface{}, p promise.Promise) stdcontext.Context {
+	return stdcontext.WithValue(ctx, key, p)
+}
+
+// PromiseFromContext returns the promise stored in ctx for key (see
+// IntoContext), and whether there was a promise for key
+func PromiseFromContext(ctx stdcontext.Context, key interface{}) (promise.Promise, bool) {
+	p, ok := ctx.Value(key).(promise.Promise)
+
+	return p, ok
+}
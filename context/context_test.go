@@ -0,0 +1,53 @@
+package context
+
+import (
+	stdcontext "context"
+	"testing"
+
+	promise "github.com/gotomgo/go-promises"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+
+	p := FromContext(ctx)
+	assert.True(t, p.IsPending())
+
+	cancel()
+
+	p.Wait(make(chan promise.Controller, 1))
+	assert.True(t, p.IsCanceled())
+}
+
+func TestFromCanceledContext(t *testing.T) {
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	cancel()
+
+	assert.True(t, FromContext(ctx).IsCanceled())
+}
+
+func TestFromContextDelivered(t *testing.T) {
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+
+	p := FromContext(ctx)
+	p.Succeed()
+
+	cancel()
+
+	assert.True(t, p.IsSuccess())
+}
+
+func TestIntoContext(t *testing.T) {
+	type key struct{}
+
+	p := promise.NewPromise()
+	ctx := IntoContext(stdcontext.Background(), key{}, p)
+
+	p2, ok := PromiseFromContext(ctx, key{})
+	assert.True(t, ok)
+	assert.Equal(t, p, p2)
+
+	_, ok = PromiseFromContext(stdcontext.Background(), key{})
+	assert.False(t, ok)
+}
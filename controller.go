@@ -5,6 +5,10 @@ import "fmt"
 // ErrPromiseCanceled is used as the error result when a Promise is canceled
 var ErrPromiseCanceled = fmt.Errorf("The promise delivery was canceled")
 
+// ErrPromiseTimeout is used as the error result when a Promise is not
+// delivered before its deadline
+var ErrPromiseTimeout = fmt.Errorf("The promise was not delivered before the deadline")
+
 // Controller is an interface for controlling the state / result of
 // the Promise
 type Controller interface {
@@ -89,4 +93,66 @@ type Controller interface {
 
 	// IsCanceled determines if the promise delivery has been canceled
 	IsCanceled() bool
+
+	// DebugMode returns a Controller for this promise that logs all handler
+	// registrations, handler invocations, and deliveries via slog.Debug
+	//
+	//  Notes
+	//    DebugMode is only enabled in builds using the promise_debug build
+	//    tag. In other builds the promise is returned unchanged
+	//
+	DebugMode() Controller
+
+	// WithMaxHandlers sets a limit on the number of handlers registered
+	// with this promise, which panics when it is exceeded, and returns the
+	// Controller
+	//
+	//  Notes
+	//    This is a debugging guard for promises that accumulate handlers by
+	//    mistake (e.g. a handler registered in a loop). n == 0 means no
+	//    limit
+	//
+	//    Every handler counts toward the limit, whether it is registered
+	//    directly (Success, Catch, Canceled, Always) or on behalf of the
+	//    caller (Then, Timeout, Wait, etc.)
+	//
+	//    WithMaxHandlers is only enabled in builds using the promise_debug
+	//    build tag. In other builds the promise is returned unchanged
+	//
+	WithMaxHandlers(n int) Controller
+
+	// WithHandlerOrder sets the order in which the handlers of this promise
+	// are invoked, and returns the Controller
+	//
+	//  Notes
+	//    The default order is FIFO. LIFO invokes the handlers in the reverse
+	//    order of registration, like deferred functions, which suits cleanup
+	//    handlers. The order applies to all handler types, and must be set
+	//    before the promise is delivered
+	//
+	WithHandlerOrder(order HandlerOrder) Controller
+
+	// WithPriority returns a Controller for this promise that registers
+	// handlers (via Success, Catch, Canceled and Always) with priority
+	//
+	//  Notes
+	//    Handlers with a higher priority are invoked first. See
+	//    SuccessPriority
+	//
+	//    The priority also applies to SuccessCtx, CatchCtx and AlwaysCtx,
+	//    and is kept by DebugMode, WithMaxHandlers and WithHandlerOrder.
+	//    The chaining methods (Then, Thenf, etc.) and Wait register their
+	//    handlers at the default priority
+	//
+	WithPriority(priority int) Controller
+
+	// String returns the state of the promise for debugging, implementing
+	// fmt.Stringer
+	//
+	//  Notes
+	//    Returns one of "Promise[Pending]", "Promise[Succeeded: <result>]",
+	//    "Promise[Failed: <err>]" or "Promise[Canceled]", where the result
+	//    is formatted via %v and truncated to 64 characters
+	//
+	String() string
 }
@@ -0,0 +1,87 @@
+package promise
+
+import (
+	"fmt"
+	"log"
+)
+
+// correlator is implemented by promises that carry a correlation id
+type correlator interface {
+	getCorrelationID() string
+}
+
+// Correlate returns a new promise that is delivered with the result of p,
+// and carries a correlation id, which is included in the log messages
+// (handler panics, double deliveries) of the returned promise
+//
+//	Notes
+//		p itself is not modified, as it may be shared with other callers
+//		(or be a package level promise, such as the result of All()), so
+//		handlers should be registered on the returned promise (see Wrap)
+//
+//		This is a lightweight alternative to full tracing for correlating
+//		promise log messages with a request
+//
+func Correlate(id string, p Promise) Promise {
+	result := newPromise()
+
+	result.setCorrelationID(id)
+
+	p.Always(func(p2 Controller) {
+		result.tryDeliver(p2.RawResult())
+	})
+
+	return result
+}
+
+// CorrelationID returns the correlation id associated with p via Correlate,
+// or "" if there is none
+func CorrelationID(p Promise) string {
+	if c, ok := p.(correlator); ok {
+		return c.getCorrelationID()
+	}
+
+	return ""
+}
+
+// setCorrelationID sets the correlation id of the promise
+func (p *promise) setCorrelationID(id string) {
+	p.correlationID.Store(&id)
+}
+
+// getCorrelationID returns the correlation id of the promise, or ""
+func (p *promise) getCorrelationID() string {
+	if id := p.correlationID.Load(); id != nil {
+		return *id
+	}
+
+	return ""
+}
+
+// logf logs a message for the promise, including the correlation id (if
+// any)
+//
+//	Notes
+//		The message is logged via the logger from WithLogger if there is one,
+//		otherwise via the standard logger
+//
+func (p *promise) logf(format string, args ...interface{}) {
+	id := p.getCorrelationID()
+
+	if p.ext != nil && p.ext.logger != nil {
+		var attrs []interface{}
+		if id != "" {
+			attrs = append(attrs, "correlation_id", id)
+		}
+
+		p.ext.logger.Warn(fmt.Sprintf(format, args...), attrs...)
+		return
+	}
+
+	if id != "" {
+		format += " correlation_id=%s"
+		args = append(args, id)
+	}
+
+	log.Printf(format, args...)
+}
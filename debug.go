@@ -0,0 +1,324 @@
+//go:build promise_debug
+
+package promise
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// debugController wraps a Controller and logs all handler registrations,
+// handler invocations, and deliveries
+//
+//	Notes
+//		The chaining methods (Then, Thenf, ThenAll, etc.) are promoted from
+//		the wrapped Controller, and register their handlers with it directly,
+//		so they are not logged individually. Their deliveries are logged
+//		when made via this controller
+//
+type debugController struct {
+	Controller
+}
+
+var _ Controller = &debugController{}
+
+// DebugMode returns a Controller for this promise that logs all handler
+// registrations, handler invocations, and deliveries via slog.Debug
+func (p *promise) DebugMode() Controller {
+	return &debugController{Controller: p}
+}
+
+// DebugMode returns a Controller for the promise that logs, and registers
+// handlers with the priority
+func (p *priorityController) DebugMode() Controller {
+	return &debugController{Controller: p}
+}
+
+// DebugMode returns the debug controller, as it is already in debug mode
+func (d *debugController) DebugMode() Controller {
+	return d
+}
+
+// WithHandlerOrder sets the order in which the handlers are invoked
+func (d *debugController) WithHandlerOrder(order HandlerOrder) Controller {
+	d.log("WithHandlerOrder", "order", order)
+	d.Controller.WithHandlerOrder(order)
+
+	return d
+}
+
+// getCorrelationID returns the correlation id of the wrapped Controller,
+// so CorrelationID works for the debug controller
+func (d *debugController) getCorrelationID() string {
+	return CorrelationID(d.Controller)
+}
+
+// handlerContext returns the context passed to context handlers, as
+// determined by the wrapped Controller
+func (d *debugController) handlerContext(ctx context.Context) context.Context {
+	if h, ok := d.Controller.(interface {
+		handlerContext(context.Context) context.Context
+	}); ok {
+		return h.handlerContext(ctx)
+	}
+
+	if ctx == nil {
+		return context.Background()
+	}
+
+	return ctx
+}
+
+// log emits a debug log entry for method
+func (d *debugController) log(method string, args ...interface{}) {
+	attrs := []interface{}{
+		"promise", fmt.Sprintf("%p", d.Controller),
+		"method", method,
+		"goroutine", goroutineID(),
+		"time", time.Now(),
+	}
+
+	if id := CorrelationID(d.Controller); id != "" {
+		attrs = append(attrs, "correlation_id", id)
+	}
+
+	slog.Debug("promise: "+method, append(attrs, args...)...)
+}
+
+// logPanic logs a handler panic and then re-panics so the panic is handled
+// exactly as it would be without debug mode
+func (d *debugController) logPanic(method string) {
+	if r := recover(); r != nil {
+		d.log(method, "panic", r)
+		panic(r)
+	}
+}
+
+// goroutineID extracts the id of the current goroutine from its stack trace
+func goroutineID() uint64 {
+	var buf [64]byte
+
+	// the stack trace starts with "goroutine <id> [...]"
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+
+	return id
+}
+
+// Success registers a callback on successful delivery of the promise
+func (d *debugController) Success(handler SuccessHandler) Promise {
+	d.log("Success")
+
+	d.Controller.Success(func(result interface{}) {
+		defer d.logPanic("Success handler")
+
+		d.log("Success handler", "result", result)
+		handler(result)
+	})
+
+	return d
+}
+
+// Catch registers a callback on a failed delivery of the promise
+func (d *debugController) Catch(handler CatchHandler) Promise {
+	d.log("Catch")
+
+	d.Controller.Catch(func(err error) {
+		defer d.logPanic("Catch handler")
+
+		d.log("Catch handler", "err", err)
+		handler(err)
+	})
+
+	return d
+}
+
+// Canceled registers a callback for the case where the promise delivery
+// is canceled
+func (d *debugController) Canceled(handler CanceledHandler) Promise {
+	d.log("Canceled")
+
+	d.Controller.Canceled(func() {
+		defer d.logPanic("Canceled handler")
+
+		d.log("Canceled handler")
+		handler()
+	})
+
+	return d
+}
+
+// Always registers a callback when the promise is delivered or canceled
+func (d *debugController) Always(handler AlwaysHandler) Promise {
+	d.log("Always")
+
+	d.Controller.Always(func(p Controller) {
+		defer d.logPanic("Always handler")
+
+		d.log("Always handler", "result", p.RawResult())
+		handler(d)
+	})
+
+	return d
+}
+
+// SuccessCtx registers a callback on successful delivery of the promise
+// that receives ctx
+func (d *debugController) SuccessCtx(ctx context.Context, handler SuccessHandlerWithContext) Promise {
+	ctx = d.handlerContext(ctx)
+
+	return d.Success(func(result interface{}) {
+		handler(ctx, result)
+	})
+}
+
+// CatchCtx registers a callback on a failed delivery of the promise that
+// receives ctx
+func (d *debugController) CatchCtx(ctx context.Context, handler CatchHandlerWithContext) Promise {
+	ctx = d.handlerContext(ctx)
+
+	return d.Catch(func(err error) {
+		handler(ctx, err)
+	})
+}
+
+// AlwaysCtx registers a callback when the promise is delivered or canceled
+// that receives ctx
+func (d *debugController) AlwaysCtx(ctx context.Context, handler AlwaysHandlerWithContext) Promise {
+	ctx = d.handlerContext(ctx)
+
+	return d.Always(func(p Controller) {
+		handler(ctx, p)
+	})
+}
+
+// Wait blocks until the promise is delivered or canceled
+func (d *debugController) Wait(waitChan chan Controller) Promise {
+	d.log("Wait")
+
+	d.Always(func(p Controller) {
+		waitChan <- p
+	})
+
+	return <-waitChan
+}
+
+// Signal uses a channel as a signal when the promise is delivered without
+// blocking
+func (d *debugController) Signal(waitChan chan Controller) Promise {
+	d.log("Signal")
+
+	d.Always(func(p Controller) {
+		waitChan <- p
+	})
+
+	return d
+}
+
+// Checkpoint registers a named checkpoint with the promise
+func (d *debugController) Checkpoint(name string, fn func(name string, p Controller)) Promise {
+	d.log("Checkpoint", "name", name)
+	d.Controller.Checkpoint(name, fn)
+
+	return d
+}
+
+// Succeed delivers the promise with a value of true
+func (d *debugController) Succeed() Controller {
+	d.log("Succeed", "pending", d.IsPending())
+	d.Controller.Succeed()
+
+	return d
+}
+
+// SucceedWithResult delivers the promise successfully with the specified
+// result
+func (d *debugController) SucceedWithResult(result interface{}) Controller {
+	d.log("SucceedWithResult", "pending", d.IsPending(), "result", result)
+	d.Controller.SucceedWithResult(result)
+
+	return d
+}
+
+// DeliverWithPromise delivers the promise based on the result of a
+// different Promise (Controller)
+func (d *debugController) DeliverWithPromise(promise Controller) Controller {
+	d.log("DeliverWithPromise", "pending", d.IsPending(), "result", promise.RawResult())
+	d.Controller.DeliverWithPromise(promise)
+
+	return d
+}
+
+// Deliver delivers the promise and based on the type of the result,
+// determines the success or failure
+func (d *debugController) Deliver(result interface{}) Controller {
+	d.log("Deliver", "pending", d.IsPending(), "result", result)
+	d.Controller.Deliver(result)
+
+	return d
+}
+
+// Fail fails the deliver of the promise with an error
+func (d *debugController) Fail(err error) Controller {
+	d.log("Fail", "pending", d.IsPending(), "err", err)
+	d.Controller.Fail(err)
+
+	return d
+}
+
+// Cancel cancels the promise
+func (d *debugController) Cancel() Controller {
+	d.log("Cancel", "pending", d.IsPending())
+	d.Controller.Cancel()
+
+	return d
+}
+
+// handlerLimit counts the handlers registered with a promise, for
+// WithMaxHandlers
+type handlerLimit struct {
+	max   atomic.Int64
+	count atomic.Int64
+}
+
+// WithMaxHandlers sets the limit of handlers that can be registered with
+// this promise, and returns this promise
+//
+//	Notes
+//		Every registration counts toward the limit, including those made
+//		on behalf of the caller (Then, Timeout, Wait, etc.), and those made
+//		before the limit was set
+//
+func (p *promise) WithMaxHandlers(n int) Controller {
+	p.limit.max.Store(int64(n))
+
+	return p
+}
+
+// WithMaxHandlers sets the handler limit of the promise
+func (d *debugController) WithMaxHandlers(n int) Controller {
+	d.Controller.WithMaxHandlers(n)
+
+	return d
+}
+
+// countHandler counts a handler registration, and panics if the limit is
+// exceeded
+func (p *promise) countHandler(method string) {
+	count := p.limit.count.Add(1)
+
+	if max := p.limit.max.Load(); max > 0 && count > max {
+		panic(fmt.Errorf("promise %p: %s registers handler %d, exceeding the limit of %d",
+			p, method, count, max))
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !promise_debug
+
+package promise
+
+// DebugMode returns the promise unchanged, as debug logging is only
+// available in builds using the promise_debug build tag
+func (p *promise) DebugMode() Controller {
+	return p
+}
+
+// DebugMode returns the priority controller unchanged, as debug logging is
+// only available in builds using the promise_debug build tag
+func (p *priorityController) DebugMode() Controller {
+	return p
+}
+
+// WithMaxHandlers returns the promise unchanged, as the handler limit is
+// only enforced in builds using the promise_debug build tag
+func (p *promise) WithMaxHandlers(n int) Controller {
+	return p
+}
+
+// handlerLimit is empty, as the handler limit is only enforced in builds
+// using the promise_debug build tag
+type handlerLimit struct{}
+
+// countHandler does nothing, as handler registrations are only counted in
+// builds using the promise_debug build tag
+func (p *promise) countHandler(method string) {}
@@ -0,0 +1,117 @@
+//go:build promise_debug
+
+package promise
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugMode(t *testing.T) {
+	p := NewPromise()
+	d := p.DebugMode()
+
+	assert.NotEqual(t, p, d)
+
+	var onSuccess int
+	var onAlways int
+
+	d.Success(func(result interface{}) {
+		onSuccess++
+		assert.Equal(t, 12, result)
+	}).Always(func(p2 Controller) {
+		onAlways++
+		assert.Equal(t, d, p2)
+	})
+
+	d.SucceedWithResult(12)
+
+	assert.True(t, p.IsSuccess())
+	assert.Equal(t, 1, onSuccess)
+	assert.Equal(t, 1, onAlways)
+}
+
+func TestWithMaxHandlers(t *testing.T) {
+	p := NewPromise().WithMaxHandlers(2)
+
+	p.Success(func(interface{}) {})
+	p.Catch(func(error) {})
+
+	assert.Panics(t, func() {
+		p.Always(func(Controller) {})
+	})
+}
+
+func TestWithMaxHandlersChaining(t *testing.T) {
+	p := NewPromise().WithMaxHandlers(2)
+
+	// Then registers a handler on behalf of the caller
+	p.Then(NewPromise())
+	p.Success(func(interface{}) {})
+
+	assert.Panics(t, func() {
+		p.Then(NewPromise())
+	})
+
+	d := NewPromise().DebugMode().WithMaxHandlers(1)
+
+	d.Thenf(func() Promise { return NewPromise() })
+
+	assert.Panics(t, func() {
+		d.Wait(make(chan Controller, 1))
+	})
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestDebugModeCorrelation(t *testing.T) {
+	var buf syncBuffer
+
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	d := Correlate("req-1", NewPromise()).(Controller).DebugMode()
+	assert.Equal(t, "req-1", CorrelationID(d))
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, 1)
+	d = NewPromiseWithContext(ctx).DebugMode()
+
+	var handlerCtx context.Context
+	d.SuccessCtx(context.Background(), func(ctx context.Context, _ interface{}) {
+		handlerCtx = ctx
+	})
+
+	go d.SucceedWithResult(12)
+	d.Wait(make(chan Controller, 1))
+
+	assert.Equal(t, ctx, handlerCtx)
+
+	out := buf.String()
+	assert.True(t, strings.Contains(out, "method=Wait"), out)
+	assert.True(t, strings.Contains(out, `method="Success handler"`), out)
+}
@@ -0,0 +1,64 @@
+// Package errgroup bridges promises and golang.org/x/sync/errgroup
+package errgroup
+
+import (
+	"context"
+
+	promise "github.com/gotomgo/go-promises"
+	syncerrgroup "golang.org/x/sync/errgroup"
+)
+
+// AsErrgroup converts a promise-based workflow to an errgroup.Group
+//
+//	Notes
+//		Each promise is added to the group as a goroutine that returns the
+//		error of the promise delivery (nil on success), so g.Wait() waits
+//		for all of the promises as well as any goroutines added by the
+//		caller
+//
+//		The returned context follows errgroup semantics and is canceled when
+//		the first goroutine (or promise) in the group fails, or when Wait
+//		returns
+////		AsErrgroup takes the promises of the workflow as arguments, rather
+//		than having no arguments, so that the promises are part of the group
+//		from the start. AsErrgroup() with no promises returns an empty group,
+//		the same as errgroup.WithContext(context.Background())
+//
+func AsErrgroup(promises ...promise.Promise) (*syncerrgroup.Group, context.Context) {
+	g, ctx := syncerrgroup.WithContext(context.Background())
+
+	for _, p := range promises {
+		p := p
+
+		g.Go(func() error {
+			// buffered so that an already delivered promise does not block
+			// the synchronous Always notification inside Wait
+			return p.Wait(make(chan promise.Controller, 1)).(promise.Controller).Error()
+		})
+	}
+
+	return g, ctx
+}
+
+// Promise returns a promise that is delivered when g.Wait() returns
+//
+//	Notes
+//		The promise fails with the first error returned by a goroutine in
+//		the group, otherwise it succeeds with a value of true
+//
+//		g.Wait() is called from a new goroutine, so all calls to g.Go()
+//		should be made before calling Promise
+//
+func Promise(g *syncerrgroup.Group) promise.Promise {
+	p := promise.NewPromise()
+
+	go func() {
+		if err := g.Wait(); err != nil {
+			p.Fail(err)
+		} else {
+			p.Succeed()
+		}
+	}()
+
+	return p
+}
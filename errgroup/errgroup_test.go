@@ -0,0 +1,64 @@
+package errgroup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	promise "github.com/gotomgo/go-promises"
+	"github.com/stretchr/testify/assert"
+	syncerrgroup "golang.org/x/sync/errgroup"
+)
+
+func TestAsErrgroup(t *testing.T) {
+	p1 := promise.NewPromise()
+	p2 := promise.NewPromise()
+
+	g, _ := AsErrgroup(p1, p2)
+
+	p1.Succeed()
+	p2.SucceedWithResult(42)
+
+	assert.NoError(t, g.Wait())
+}
+
+func TestAsErrgroupCancel(t *testing.T) {
+	testErr := fmt.Errorf("Testing AsErrgroup")
+
+	p1 := promise.NewPromise()
+	p2 := promise.NewPromise()
+
+	g, ctx := AsErrgroup(p1, p2)
+
+	// the first failure cancels the context of the group
+	p1.Fail(testErr)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the context was not canceled by the failure")
+	}
+
+	// a later failure does not replace the first error
+	p2.Fail(fmt.Errorf("Testing AsErrgroup again"))
+
+	assert.Equal(t, testErr, g.Wait())
+}
+
+func TestPromise(t *testing.T) {
+	var g syncerrgroup.Group
+
+	g.Go(func() error { return nil })
+
+	p := Promise(&g).Wait(make(chan promise.Controller, 1)).(promise.Controller)
+	assert.True(t, p.IsSuccess())
+
+	testErr := fmt.Errorf("Testing Promise")
+
+	g2, _ := syncerrgroup.WithContext(context.Background())
+	g2.Go(func() error { return testErr })
+
+	p = Promise(g2).Wait(make(chan promise.Controller, 1)).(promise.Controller)
+	assert.Equal(t, testErr, p.Error())
+}
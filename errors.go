@@ -0,0 +1,75 @@
+package promise
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrNotEnoughPromises is used as the error result when a combinator
+// requires more successes than there are promises
+var ErrNotEnoughPromises = fmt.Errorf("Not enough promises to succeed")
+
+// MultiError is the error of a combinator that failed because of the
+// failures of more than one promise
+type MultiError struct {
+	// Errors are the errors of the failed promises, in order of delivery
+	Errors []error
+}
+
+// Error returns the messages of all of the errors
+func (e *MultiError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d promises failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the errors for errors.Is and errors.As
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// mappedError is an error transformed by MapError, which wraps both the
+// transformed and the original error
+type mappedError struct {
+	err      error
+	original error
+}
+
+// mapError transforms err via fn, so that the result unwraps to err
+//
+//	Notes
+//		If fn returns nil or err itself, err is returned as-is. Errors of an
+//		uncomparable type (such as a struct holding a slice) are never
+//		considered the same, as comparing them would panic
+//
+func mapError(err error, fn func(error) error) error {
+	mapped := fn(err)
+	if mapped == nil || sameError(mapped, err) {
+		return err
+	}
+
+	return &mappedError{err: mapped, original: err}
+}
+
+// sameError reports whether a and b are the same error, without panicking
+// for errors of an uncomparable type
+func sameError(a, b error) bool {
+	t := reflect.TypeOf(a)
+
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// Error returns the message of the transformed error
+func (e *mappedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the transformed and original errors for errors.Is and
+// errors.As
+func (e *mappedError) Unwrap() []error {
+	return []error{e.err, e.original}
+}
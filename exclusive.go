@@ -0,0 +1,76 @@
+package promise
+
+import "sync"
+
+// ExclusiveRegistry maps keys to in-flight promises so that concurrent
+// requests for the same key share a single promise
+type ExclusiveRegistry struct {
+	lock     sync.Mutex
+	inflight map[string]Promise
+}
+
+// exclusive is the registry used by the package level Exclusive
+var exclusive = NewExclusiveRegistry()
+
+// NewExclusiveRegistry creates an empty ExclusiveRegistry
+func NewExclusiveRegistry() *ExclusiveRegistry {
+	return &ExclusiveRegistry{inflight: make(map[string]Promise)}
+}
+
+// Exclusive returns the in-flight promise for key, or if there isn't one,
+// invokes factory and registers its promise as in-flight for key
+//
+//	Notes
+//		Once the promise is delivered (success or failure) it is removed
+//		from the registry, and the next call for key invokes factory again
+//
+//		factory is invoked while the registry is locked, so it should only
+//		start the work and return a promise, and not wait for the delivery
+//
+func (r *ExclusiveRegistry) Exclusive(key string, factory Factory) Promise {
+	r.lock.Lock()
+
+	if p, ok := r.inflight[key]; ok {
+		r.lock.Unlock()
+		return p
+	}
+
+	p := factory()
+	r.inflight[key] = p
+
+	alwaysAfterUnlock(&r.lock, p, func(Controller) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		// only remove the entry if it still refers to this promise
+		if r.inflight[key] == p {
+			delete(r.inflight, key)
+		}
+	})
+
+	return p
+}
+
+// Exclusive uses a process-wide registry to share one in-flight promise
+// among all concurrent callers for key
+//
+//	Notes
+//		See ExclusiveRegistry.Exclusive
+//
+func Exclusive(key string, factory Factory) Promise {
+	return exclusive.Exclusive(key, factory)
+}
+
+// alwaysAfterUnlock releases lock, and then registers handler as an Always
+// handler of p
+//
+//	Notes
+//		The lock must be released before the handler is registered, as the
+//		handler is invoked synchronously if p is already delivered, and the
+//		handler typically acquires the same lock
+//
+func alwaysAfterUnlock(lock sync.Locker, p Promise, handler AlwaysHandler) {
+	lock.Unlock()
+
+	p.Always(handler)
+}
@@ -0,0 +1,135 @@
+// Package exec wraps the execution of an os/exec.Cmd as a promise
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	osexec "os/exec"
+	"syscall"
+
+	promise "github.com/gotomgo/go-promises"
+)
+
+// ExecResult is the result of running a command
+type ExecResult struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// ExecError is used as the error result when a command fails to start or
+// exits with a non-zero status
+type ExecError struct {
+	*ExecResult
+
+	// Err is the error returned by the command
+	Err error
+}
+
+// Error returns the text of the error
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("command failed (exit code %d): %s", e.ExitCode, e.Err)
+}
+
+// Unwrap returns the error returned by the command
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// RunPromise runs cmd asynchronously and returns a promise for the result
+//
+//	Notes
+//		The output of the command is captured (in addition to any writers
+//		already assigned to cmd.Stdout / cmd.Stderr). The promise succeeds
+//		with an *ExecResult, or fails with an *ExecError
+//
+func RunPromise(cmd *osexec.Cmd) promise.Promise {
+	return run(cmd, nil)
+}
+
+// CancelWithContext runs cmd asynchronously and sends SIGTERM to the
+// process when ctx is done
+//
+//	Notes
+//		See RunPromise. A command terminated by the signal fails with an
+//		*ExecError
+//
+func CancelWithContext(ctx context.Context, cmd *osexec.Cmd) promise.Promise {
+	return run(cmd, func() func() bool {
+		return context.AfterFunc(ctx, func() {
+			cmd.Process.Signal(syscall.SIGTERM)
+		})
+	})
+}
+
+// OutputPromise runs cmd asynchronously and returns a promise for the
+// standard output of the command
+//
+//	Notes
+//		See RunPromise
+//
+func OutputPromise(cmd *osexec.Cmd) promise.TypedPromise[[]byte] {
+	result := promise.NewTypedPromise[[]byte]()
+
+	RunPromise(cmd).Success(func(res interface{}) {
+		result.SucceedWithResult(res.(*ExecResult).Stdout)
+	}).Catch(func(err error) {
+		result.Fail(err)
+	})
+
+	return result
+}
+
+// run starts cmd and delivers the promise when it exits
+//
+//	Notes
+//		If not nil, started is invoked once the process is started, and
+//		the function it returns is invoked when the process exits
+//
+func run(cmd *osexec.Cmd, started func() func() bool) promise.Promise {
+	p := promise.NewPromise()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd.Stdout = capture(cmd.Stdout, &stdout)
+	cmd.Stderr = capture(cmd.Stderr, &stderr)
+
+	if err := cmd.Start(); err != nil {
+		return p.Fail(&ExecError{ExecResult: &ExecResult{ExitCode: -1}, Err: err})
+	}
+
+	stop := func() bool { return false }
+	if started != nil {
+		stop = started()
+	}
+
+	go func() {
+		err := cmd.Wait()
+		stop()
+
+		result := &ExecResult{
+			Stdout:   stdout.Bytes(),
+			Stderr:   stderr.Bytes(),
+			ExitCode: cmd.ProcessState.ExitCode(),
+		}
+
+		if err != nil {
+			p.Fail(&ExecError{ExecResult: result, Err: err})
+		} else {
+			p.SucceedWithResult(result)
+		}
+	}()
+
+	return p
+}
+
+// capture returns a writer that writes to buf as well as w (if not nil)
+func capture(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+
+	return io.MultiWriter(w, buf)
+}
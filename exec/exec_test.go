@@ -0,0 +1,46 @@
+package exec
+
+import (
+	"context"
+	osexec "os/exec"
+	"testing"
+
+	promise "github.com/gotomgo/go-promises"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPromise(t *testing.T) {
+	p := RunPromise(osexec.Command("go", "version")).Wait(make(chan promise.Controller, 1)).(promise.Controller)
+
+	assert.True(t, p.IsSuccess())
+
+	result := p.Result().(*ExecResult)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Contains(t, string(result.Stdout), "go version")
+}
+
+func TestRunPromiseFail(t *testing.T) {
+	p := RunPromise(osexec.Command("go", "not-a-command")).Wait(make(chan promise.Controller, 1)).(promise.Controller)
+
+	err := p.Error().(*ExecError)
+	assert.NotEqual(t, 0, err.ExitCode)
+	assert.NotEmpty(t, err.Stderr)
+}
+
+func TestCancelWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := CancelWithContext(ctx, osexec.Command("go", "version")).Wait(make(chan promise.Controller, 1)).(promise.Controller)
+
+	assert.True(t, p.IsDelivered())
+}
+
+func TestOutputPromise(t *testing.T) {
+	p := OutputPromise(osexec.Command("go", "version"))
+	p.Wait(make(chan promise.Controller, 1))
+
+	stdout, ok := p.TypedResult()
+	assert.True(t, ok)
+	assert.Contains(t, string(stdout), "go version")
+}
@@ -0,0 +1,33 @@
+package promise
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrInjectedFailure is the error of the failed promises returned by a
+// factory created via FactoryFailNTimes
+var ErrInjectedFailure = fmt.Errorf("Injected promise failure")
+
+// FactoryFailNTimes wraps factory so that the first n calls return a promise
+// that has failed with ErrInjectedFailure, and subsequent calls invoke
+// factory
+//
+//	Notes
+//		This is intended for testing retry and circuit-breaker logic, where
+//		an operation must fail a known number of times before it succeeds
+//
+//		The calls are counted atomically, so the wrapped factory can be
+//		invoked concurrently
+//
+func FactoryFailNTimes(n int, factory Factory) Factory {
+	var calls int64
+
+	return func() Promise {
+		if atomic.AddInt64(&calls, 1) <= int64(n) {
+			return newPromise().Fail(ErrInjectedFailure)
+		}
+
+		return factory()
+	}
+}
@@ -0,0 +1,56 @@
+package promise
+
+import "fmt"
+
+// Future is the result of an asynchronous operation that is obtained by
+// blocking until the operation completes
+type Future interface {
+	// Get blocks until the result is available, and returns the result or
+	// the error
+	Get() (interface{}, error)
+}
+
+// TypedFuture is a Future whose result is of type T
+type TypedFuture[T any] interface {
+	// Get blocks until the result is available, and returns the result or
+	// the error
+	Get() (T, error)
+}
+
+// future implements Future for a Promise
+type future struct {
+	promise Promise
+}
+
+// typedFuture implements TypedFuture for a TypedPromise
+type typedFuture[T any] struct {
+	promise TypedPromise[T]
+}
+
+// Get blocks until the promise is delivered
+func (f *future) Get() (interface{}, error) {
+	return Unwrap(f.promise)
+}
+
+// ToTypedFuture returns a TypedFuture backed by p
+//
+//	Notes
+//		Get fails if p succeeds with a result that is not a T
+//
+func ToTypedFuture[T any](p TypedPromise[T]) TypedFuture[T] {
+	return &typedFuture[T]{promise: p}
+}
+
+// Get blocks until the promise is delivered
+func (f *typedFuture[T]) Get() (result T, err error) {
+	if _, err = Unwrap(f.promise); err != nil {
+		return
+	}
+
+	result, ok := f.promise.TypedResult()
+	if !ok {
+		err = fmt.Errorf("promise result is not a %T", result)
+	}
+
+	return
+}
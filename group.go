@@ -0,0 +1,93 @@
+package promise
+
+import "sync"
+
+// SettledResult is the outcome of a delivered promise
+type SettledResult struct {
+	// Succeeded is true if the promise succeeded
+	Succeeded bool
+
+	// Result is the result of a successful promise
+	Result interface{}
+
+	// Err is the error of a failed (or canceled) promise
+	Err error
+}
+
+// PromiseSettledResult is the outcome of a delivered promise, as reported
+// by ThenAllSettled
+type PromiseSettledResult = SettledResult
+
+// Group waits for a collection of promises, like sync.WaitGroup, but also
+// collects the outcome of each promise
+//
+//	Notes
+//		The zero value is an empty Group ready to use, and a Group can be
+//		used from multiple goroutines concurrently
+//
+//		Wait and Results consider the promises added before they are called.
+//		As with sync.WaitGroup, promises should be added before waiting
+//
+type Group struct {
+	lock     sync.Mutex
+	promises []Promise
+}
+
+// Add adds p to the group, whether or not p is already delivered
+func (g *Group) Add(p Promise) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.promises = append(g.promises, p)
+}
+
+// members returns a snapshot of the promises in the group
+func (g *Group) members() []Promise {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return append([]Promise(nil), g.promises...)
+}
+
+// Wait returns a promise that succeeds when all of the promises in the
+// group have been delivered, regardless of the outcome of each delivery
+func (g *Group) Wait() Promise {
+	return settle(g.members())
+}
+
+// Cancel cancels each promise in the group that is still pending
+func (g *Group) Cancel() {
+	for _, p := range g.members() {
+		cancelPending(p)
+	}
+}
+
+// Results returns a promise that succeeds when all of the promises in the
+// group have been delivered
+//
+//	Notes
+//		The result of the returned promise is a []SettledResult with the
+//		outcome of each promise, in the order the promises were added
+//
+func (g *Group) Results() Promise {
+	return settledResults(g.members())
+}
+
+// settledResults returns a promise that succeeds with a []SettledResult
+// once all of the promises have been delivered
+func settledResults(promises []Promise) Promise {
+	return settledControllers(promises).ThenWithResult(func(result interface{}) Promise {
+		controllers := result.([]Controller)
+		results := make([]SettledResult, len(controllers))
+
+		for i, p2 := range controllers {
+			if p2.IsSuccess() {
+				results[i] = SettledResult{Succeeded: true, Result: p2.Result()}
+			} else {
+				results[i] = SettledResult{Err: p2.Error()}
+			}
+		}
+
+		return newPromise().SucceedWithResult(results)
+	})
+}
@@ -0,0 +1,98 @@
+// Package grpc wraps gRPC client calls as promises
+package grpc
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	promise "github.com/gotomgo/go-promises"
+	stdgrpc "google.golang.org/grpc"
+)
+
+// UnaryPromise invokes a typed gRPC unary stub asynchronously
+//
+//	Notes
+//		call is typically a method value of a generated client, such as
+//		client.GetUser. Canceling the promise cancels the context of the
+//		call
+//
+func UnaryPromise[Req, Resp any](ctx context.Context, call func(context.Context, Req, ...stdgrpc.CallOption) (Resp, error), req Req, opts ...stdgrpc.CallOption) promise.TypedPromise[Resp] {
+	p := promise.NewTypedPromise[Resp]()
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	// canceling the promise cancels the call
+	p.Canceled(promise.CanceledHandler(cancel))
+
+	go func() {
+		defer cancel()
+
+		resp, err := call(ctx, req, opts...)
+
+		// the promise was canceled while the call was in-flight?
+		if p.IsCanceled() {
+			return
+		}
+
+		if err != nil {
+			p.Fail(err)
+		} else {
+			p.SucceedWithResult(resp)
+		}
+	}()
+
+	return p
+}
+
+// ServerStreamPromise receives all of the messages of a server stream
+//
+//	Notes
+//		The promise succeeds with a []Resp once the stream ends (io.EOF),
+//		or fails with the first receive error
+//
+//		Resp is typically a pointer to a generated message type, in which
+//		case a new message is allocated for each receive
+//
+//		The stream belongs to the caller, so to cancel the stream, cancel
+//		the context used to create it
+//
+func ServerStreamPromise[Resp any](stream stdgrpc.ClientStream) promise.Promise {
+	p := promise.NewPromise()
+
+	go func() {
+		var results []Resp
+
+		for {
+			msg, target := newMessage[Resp]()
+
+			if err := stream.RecvMsg(target); err != nil {
+				if err == io.EOF {
+					p.SucceedWithResult(results)
+				} else {
+					p.Fail(err)
+				}
+
+				return
+			}
+
+			results = append(results, *msg)
+		}
+	}()
+
+	return p
+}
+
+// newMessage allocates storage for a Resp, and returns the storage along
+// with the value to pass to RecvMsg
+func newMessage[Resp any]() (*Resp, interface{}) {
+	msg := new(Resp)
+
+	// for pointer types, allocate the message the pointer refers to
+	if t := reflect.TypeOf(msg).Elem(); t.Kind() == reflect.Ptr {
+		reflect.ValueOf(msg).Elem().Set(reflect.New(t.Elem()))
+		return msg, *msg
+	}
+
+	return msg, msg
+}
@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	promise "github.com/gotomgo/go-promises"
+	"github.com/stretchr/testify/assert"
+	stdgrpc "google.golang.org/grpc"
+)
+
+type request struct {
+	id int
+}
+
+type response struct {
+	name string
+}
+
+func TestUnaryPromise(t *testing.T) {
+	call := func(ctx context.Context, req *request, opts ...stdgrpc.CallOption) (*response, error) {
+		return &response{name: "user"}, nil
+	}
+
+	p := UnaryPromise(context.Background(), call, &request{id: 1})
+	p.Wait(make(chan promise.Controller, 1))
+
+	resp, ok := p.TypedResult()
+	assert.True(t, ok)
+	assert.Equal(t, "user", resp.name)
+}
+
+func TestUnaryPromiseCanceled(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+
+	call := func(ctx context.Context, req *request, opts ...stdgrpc.CallOption) (*response, error) {
+		close(started)
+
+		// canceling the promise cancels the call
+		<-ctx.Done()
+		close(done)
+
+		return &response{}, nil
+	}
+
+	p := UnaryPromise(context.Background(), call, &request{id: 1}).(promise.TypedController[*response])
+
+	<-started
+	p.Cancel()
+	<-done
+
+	assert.True(t, p.IsCanceled())
+}
@@ -0,0 +1,137 @@
+package promise
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// HandlerOrder is the order in which the handlers of a promise are invoked
+type HandlerOrder int
+
+const (
+	// FIFO invokes handlers in the order they were registered (the default)
+	FIFO HandlerOrder = iota
+
+	// LIFO invokes handlers in the reverse order they were registered, like
+	// deferred functions
+	LIFO
+)
+
+// handlerNode is an entry in a handlerList
+type handlerNode struct {
+	handler  interface{}
+	priority int
+	next     *handlerNode
+}
+
+// closedHandlers is the head of every closed handlerList
+var closedHandlers = &handlerNode{}
+
+// handlerList is a lock-free, singly-linked list of handlers of type H
+//
+//	Notes
+//		Handlers are atomically prepended to the list, so the list is in the
+//		reverse order of registration until it is closed
+//
+//		Once closed (when the promise is delivered) no more handlers can be
+//		added to the list, which allows a registration to detect that it
+//		raced with delivery and must invoke the handler directly
+//
+type handlerList[H any] struct {
+	head atomic.Pointer[handlerNode]
+}
+
+// add adds a handler to the list, and returns false if the list is closed
+func (l *handlerList[H]) add(handler H) bool {
+	return l.addPriority(0, handler)
+}
+
+// addPriority adds a handler with a priority to the list, and returns false
+// if the list is closed
+func (l *handlerList[H]) addPriority(priority int, handler H) bool {
+	head := l.head.Load()
+	if head == closedHandlers {
+		return false
+	}
+
+	node := &handlerNode{handler: handler, priority: priority}
+
+	for {
+		node.next = head
+		if l.head.CompareAndSwap(head, node) {
+			return true
+		}
+
+		// another handler was added, or the list was closed
+		head = l.head.Load()
+		if head == closedHandlers {
+			return false
+		}
+	}
+}
+
+// close closes the list to further additions, and returns the handlers
+// in the specified order
+//
+//	Notes
+//		For FIFO, the list is reversed in place, as the caller now
+//		exclusively owns the nodes, so no copy of the handlers is made
+//
+func (l *handlerList[H]) close(order HandlerOrder) *handlerNode {
+	head := l.head.Swap(closedHandlers)
+
+	// guard against closing twice
+	if head == closedHandlers {
+		return nil
+	}
+
+	// the list is already in LIFO order
+	if order == LIFO {
+		return byPriority(head)
+	}
+
+	var prev *handlerNode
+	for head != nil {
+		next := head.next
+		head.next = prev
+		prev, head = head, next
+	}
+
+	return byPriority(prev)
+}
+
+// byPriority orders the handlers by descending priority, keeping the
+// existing order of handlers with the same priority
+//
+//	Notes
+//		Handlers rarely have a priority, so the list is only sorted when at
+//		least one handler has a non-zero priority
+//
+func byPriority(head *handlerNode) *handlerNode {
+	prioritized := false
+	for node := head; node != nil && !prioritized; node = node.next {
+		prioritized = node.priority != 0
+	}
+
+	if !prioritized {
+		return head
+	}
+
+	var nodes []*handlerNode
+	for node := head; node != nil; node = node.next {
+		nodes = append(nodes, node)
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].priority > nodes[j].priority
+	})
+
+	for i, node := range nodes {
+		node.next = nil
+		if i > 0 {
+			nodes[i-1].next = node
+		}
+	}
+
+	return nodes[0]
+}
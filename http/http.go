@@ -0,0 +1,123 @@
+// Package http wraps the net/http request lifecycle as promises
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	nethttp "net/http"
+
+	promise "github.com/gotomgo/go-promises"
+)
+
+// StatusError is used as the error result when a response has a non-2xx
+// status code
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+// Error returns the text of the error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP STATUS (%d): %s", e.StatusCode, e.Status)
+}
+
+// FromHTTPResponse converts the (response, error) result of a request to
+// a delivered promise
+//
+//	Notes
+//		The promise fails with err if err != nil, or with a *StatusError if
+//		the status code is not 2xx (in which case the body is closed).
+//		Otherwise the promise succeeds with resp, and the caller is
+//		responsible for closing the body
+//
+func FromHTTPResponse(resp *nethttp.Response, err error) promise.Promise {
+	p := promise.NewPromise()
+
+	deliver(p, resp, err)
+
+	return p
+}
+
+// FetchPromise executes req asynchronously and returns a promise for the
+// response
+//
+//	Notes
+//		The promise is delivered as described by FromHTTPResponse. Canceling
+//		the promise cancels the request
+//
+func FetchPromise(client *nethttp.Client, req *nethttp.Request) promise.Promise {
+	return fetch(client, req, promise.NewPromise())
+}
+
+// FetchJSONPromise executes req asynchronously and decodes the JSON body
+// of a successful response into a T
+//
+//	Notes
+//		The body is always closed. The promise fails as described by
+//		FromHTTPResponse, or with the error from decoding the body
+//
+func FetchJSONPromise[T any](client *nethttp.Client, req *nethttp.Request) promise.TypedPromise[T] {
+	result := promise.NewTypedPromise[T]()
+
+	FetchPromise(client, req).Success(func(res interface{}) {
+		body := res.(*nethttp.Response).Body
+		defer body.Close()
+
+		var value T
+		if err := json.NewDecoder(body).Decode(&value); err != nil {
+			result.Fail(err)
+		} else {
+			result.SucceedWithResult(value)
+		}
+	}).Catch(func(err error) {
+		result.Fail(err)
+	})
+
+	return result
+}
+
+// fetch executes req in a goroutine and delivers p with the response
+func fetch(client *nethttp.Client, req *nethttp.Request, p promise.Controller) promise.Promise {
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+
+	// canceling the promise cancels the request
+	p.Canceled(promise.CanceledHandler(cancel))
+
+	go func() {
+		resp, err := client.Do(req)
+
+		if p.IsCanceled() {
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			return
+		}
+
+		deliver(p, resp, err)
+	}()
+
+	return p
+}
+
+// deliver delivers p based on the (response, error) result of a request
+func deliver(p promise.Controller, resp *nethttp.Response, err error) {
+	if err != nil {
+		p.Fail(err)
+		return
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// drain the body so the connection can be reused
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		p.Fail(&StatusError{StatusCode: resp.StatusCode, Status: resp.Status})
+		return
+	}
+
+	p.SucceedWithResult(resp)
+}
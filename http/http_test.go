@@ -0,0 +1,58 @@
+package http
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	promise "github.com/gotomgo/go-promises"
+	"github.com/stretchr/testify/assert"
+)
+
+func newServer() *httptest.Server {
+	return httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.URL.Path == "/missing" {
+			nethttp.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprint(w, `{"value":12}`)
+	}))
+}
+
+func TestFromHTTPResponse(t *testing.T) {
+	testErr := fmt.Errorf("Testing FromHTTPResponse")
+
+	p := FromHTTPResponse(nil, testErr).(promise.Controller)
+	assert.Equal(t, testErr, p.Error())
+}
+
+func TestFetchPromise(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	req, _ := nethttp.NewRequest("GET", server.URL+"/missing", nil)
+
+	p := FetchPromise(server.Client(), req).Wait(make(chan promise.Controller, 1)).(promise.Controller)
+
+	assert.Equal(t, nethttp.StatusNotFound, p.Error().(*StatusError).StatusCode)
+}
+
+func TestFetchJSONPromise(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	type body struct {
+		Value int `json:"value"`
+	}
+
+	req, _ := nethttp.NewRequest("GET", server.URL, nil)
+
+	p := FetchJSONPromise[body](server.Client(), req)
+	p.Wait(make(chan promise.Controller, 1))
+
+	result, ok := p.TypedResult()
+	assert.True(t, ok)
+	assert.Equal(t, 12, result.Value)
+}
@@ -0,0 +1,101 @@
+// Package io wraps blocking I/O operations as promises
+//
+//	Notes
+//		Each operation runs in its own goroutine. The returned promise is
+//		canceled when ctx is done, and the reader / writer is wrapped so that
+//		the operation stops at the next Read or Write once ctx is done
+//
+package io
+
+import (
+	"context"
+	stdio "io"
+
+	promise "github.com/gotomgo/go-promises"
+	promisecontext "github.com/gotomgo/go-promises/context"
+)
+
+// ReadAllPromise reads from r until EOF asynchronously
+//
+//	Notes
+//		The promise succeeds with the []byte read from r
+//
+func ReadAllPromise(ctx context.Context, r stdio.Reader) promise.Promise {
+	return start(ctx, func() (interface{}, error) {
+		return stdio.ReadAll(&reader{ctx: ctx, r: r})
+	})
+}
+
+// WritePromise writes data to w asynchronously
+//
+//	Notes
+//		The promise succeeds with the number of bytes written (int)
+//
+func WritePromise(ctx context.Context, w stdio.Writer, data []byte) promise.Promise {
+	return start(ctx, func() (interface{}, error) {
+		return (&writer{ctx: ctx, w: w}).Write(data)
+	})
+}
+
+// CopyPromise copies from src to dst asynchronously
+//
+//	Notes
+//		The promise succeeds with the number of bytes copied (int64)
+//
+func CopyPromise(ctx context.Context, dst stdio.Writer, src stdio.Reader) promise.Promise {
+	return start(ctx, func() (interface{}, error) {
+		return stdio.Copy(&writer{ctx: ctx, w: dst}, &reader{ctx: ctx, r: src})
+	})
+}
+
+// start runs op in a goroutine and delivers the promise with its result
+func start(ctx context.Context, op func() (interface{}, error)) promise.Promise {
+	p := promisecontext.FromContext(ctx)
+
+	if p.IsPending() {
+		go func() {
+			result, err := op()
+
+			// the promise is canceled if ctx is done
+			if p.IsPending() {
+				if err != nil {
+					p.Fail(err)
+				} else {
+					p.SucceedWithResult(result)
+				}
+			}
+		}()
+	}
+
+	return p
+}
+
+// reader is a context aware io.Reader
+type reader struct {
+	ctx context.Context
+	r   stdio.Reader
+}
+
+// Read reads from the underlying reader unless the context is done
+func (r *reader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
+
+// writer is a context aware io.Writer
+type writer struct {
+	ctx context.Context
+	w   stdio.Writer
+}
+
+// Write writes to the underlying writer unless the context is done
+func (w *writer) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return w.w.Write(p)
+}
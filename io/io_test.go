@@ -0,0 +1,48 @@
+package io
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	promise "github.com/gotomgo/go-promises"
+	"github.com/stretchr/testify/assert"
+)
+
+func wait(p promise.Promise) promise.Controller {
+	return p.Wait(make(chan promise.Controller, 1)).(promise.Controller)
+}
+
+func TestReadAllPromise(t *testing.T) {
+	p := wait(ReadAllPromise(context.Background(), strings.NewReader("promise")))
+
+	assert.Equal(t, []byte("promise"), p.Result())
+}
+
+func TestWritePromise(t *testing.T) {
+	var buf bytes.Buffer
+
+	p := wait(WritePromise(context.Background(), &buf, []byte("promise")))
+
+	assert.Equal(t, 7, p.Result())
+	assert.Equal(t, "promise", buf.String())
+}
+
+func TestCopyPromise(t *testing.T) {
+	var buf bytes.Buffer
+
+	p := wait(CopyPromise(context.Background(), &buf, strings.NewReader("promise")))
+
+	assert.Equal(t, int64(7), p.Result())
+	assert.Equal(t, "promise", buf.String())
+}
+
+func TestCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := wait(ReadAllPromise(ctx, strings.NewReader("promise")))
+
+	assert.True(t, p.IsCanceled())
+}
@@ -0,0 +1,80 @@
+package promise
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrMaxIterationsExceeded is used as the error result by LoopUntilMax when
+// stop has not returned true within the maximum number of iterations
+var ErrMaxIterationsExceeded = fmt.Errorf("Maximum loop iterations exceeded")
+
+// LoopUntil invokes factory repeatedly until stop returns true for the
+// delivery of its promise
+//
+//	Notes
+//		stop is invoked with the result and error of each promise from
+//		factory, and when it returns true, the returned promise is delivered
+//		with the same result or error
+//
+//		factory is only invoked once the previous promise is delivered, and
+//		no goroutine is blocked between iterations. If stop never returns
+//		true, the loop never ends, so prefer LoopUntilMax unless the loop is
+//		known to terminate
+//
+func LoopUntil(factory Factory, stop func(result interface{}, err error) bool) Promise {
+	return LoopUntilMax(0, factory, stop)
+}
+
+// LoopUntilMax invokes factory repeatedly until stop returns true for the
+// delivery of its promise, for at most max iterations
+//
+//	Notes
+//		If stop has not returned true after max iterations, the returned
+//		promise fails with ErrMaxIterationsExceeded. If max <= 0, the number
+//		of iterations is not limited (see LoopUntil)
+//
+//		Canceling the returned promise stops the loop
+//
+func LoopUntilMax(max int, factory Factory, stop func(result interface{}, err error) bool) Promise {
+	result := newPromise()
+	iterations := 0
+
+	var next func()
+	next = func() {
+		// promises that are already delivered notify synchronously, so the
+		// loop is trampolined to avoid recursion for each iteration
+		for !result.IsDelivered() {
+			if max > 0 && iterations >= max {
+				result.tryDeliver(ErrMaxIterationsExceeded)
+				return
+			}
+
+			iterations++
+
+			// 0: registering, 1: continue inline, 2: continue asynchronously
+			var state int32
+
+			factory().Always(func(p Controller) {
+				if stop(p.Result(), p.Error()) {
+					result.tryDeliver(p.RawResult())
+					return
+				}
+
+				// registration already returned? continue from this goroutine
+				if !atomic.CompareAndSwapInt32(&state, 0, 1) {
+					next()
+				}
+			})
+
+			// the handler will continue the loop when the promise delivers
+			if atomic.CompareAndSwapInt32(&state, 0, 2) {
+				return
+			}
+		}
+	}
+
+	next()
+
+	return result
+}
@@ -0,0 +1,76 @@
+// Package nats bridges NATS request-reply and subscriptions to promises
+package nats
+
+import (
+	"context"
+	"time"
+
+	promise "github.com/gotomgo/go-promises"
+	stdnats "github.com/nats-io/nats.go"
+)
+
+// RequestPromise sends a request to subject and delivers the reply
+//
+//	Notes
+//		The promise succeeds with the reply *nats.Msg, or fails with the
+//		error from nc.RequestWithContext, including a timeout if there is
+//		no reply within timeout. Canceling the promise cancels the request
+//
+func RequestPromise(nc *stdnats.Conn, subject string, data []byte, timeout time.Duration) promise.Promise {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	return run(ctx, func(ctx context.Context) (interface{}, error) {
+		defer cancel()
+
+		return nc.RequestWithContext(ctx, subject, data)
+	})
+}
+
+// SubscribeOnce subscribes to subject, and delivers the first message
+// received
+//
+//	Notes
+//		The promise succeeds with the *nats.Msg, and the subscription is
+//		removed once the message is received. Canceling the promise stops
+//		waiting for the message and removes the subscription
+//
+func SubscribeOnce(nc *stdnats.Conn, subject string) promise.Promise {
+	sub, err := nc.SubscribeSync(subject)
+	if err != nil {
+		return promise.NewPromise().Fail(err)
+	}
+
+	return run(context.Background(), func(ctx context.Context) (interface{}, error) {
+		defer sub.Unsubscribe()
+
+		return sub.NextMsgWithContext(ctx)
+	})
+}
+
+// run invokes call asynchronously with a context that is canceled when the
+// returned promise is canceled
+func run(ctx context.Context, call func(context.Context) (interface{}, error)) promise.Promise {
+	p := promise.NewPromise()
+
+	ctx, cancel := context.WithCancel(ctx)
+	p.Canceled(promise.CanceledHandler(cancel))
+
+	go func() {
+		defer cancel()
+
+		result, err := call(ctx)
+
+		// the promise was canceled while the call was in-flight?
+		if p.IsCanceled() {
+			return
+		}
+
+		if err != nil {
+			p.Fail(err)
+		} else {
+			p.SucceedWithResult(result)
+		}
+	}()
+
+	return p
+}
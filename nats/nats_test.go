@@ -0,0 +1,81 @@
+package nats
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	promise "github.com/gotomgo/go-promises"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use as the
+// output of the standard logger
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.String()
+}
+
+func TestRun(t *testing.T) {
+	p := run(context.Background(), func(context.Context) (interface{}, error) {
+		return "OK", nil
+	}).Wait(make(chan promise.Controller, 1)).(promise.Controller)
+
+	assert.Equal(t, "OK", p.Result())
+
+	testErr := fmt.Errorf("Testing run")
+
+	p = run(context.Background(), func(context.Context) (interface{}, error) {
+		return nil, testErr
+	}).Wait(make(chan promise.Controller, 1)).(promise.Controller)
+
+	assert.Equal(t, testErr, p.Error())
+}
+
+func TestRunCanceled(t *testing.T) {
+	var logged syncBuffer
+
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	started := make(chan struct{})
+	returned := make(chan struct{})
+
+	p := run(context.Background(), func(ctx context.Context) (interface{}, error) {
+		defer close(returned)
+
+		close(started)
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}).(promise.Controller)
+
+	<-started
+	p.Cancel()
+	<-returned
+
+	// give the goroutine the chance to (incorrectly) deliver the promise
+	time.Sleep(10 * time.Millisecond)
+
+	assert.True(t, p.IsCanceled())
+	assert.Equal(t, "", logged.String())
+}
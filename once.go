@@ -0,0 +1,86 @@
+package promise
+
+import "sync"
+
+// OnceRegistry maps keys to promises so that the factory for a key is
+// invoked once, and every caller for the key shares the same promise
+type OnceRegistry struct {
+	lock     sync.Mutex
+	promises map[string]Promise
+}
+
+// once is the registry used by the package level Once
+var once = NewOnceRegistry()
+
+// NewOnceRegistry creates an empty OnceRegistry
+func NewOnceRegistry() *OnceRegistry {
+	return &OnceRegistry{promises: make(map[string]Promise)}
+}
+
+// Once returns the promise for key, or if there isn't one, invokes factory
+// and registers its promise for key
+//
+//	Notes
+//		Unlike ExclusiveRegistry, a promise that succeeds remains in the
+//		registry, so every subsequent call for key returns the same
+//		(delivered) promise. A promise that fails (or is canceled) is
+//		removed, and the next call for key invokes factory again
+//
+//		factory is invoked while the registry is locked, so it should only
+//		start the work and return a promise, and not wait for the delivery
+//
+func (r *OnceRegistry) Once(key string, factory Factory) Promise {
+	r.lock.Lock()
+
+	if p, ok := r.promises[key]; ok {
+		r.lock.Unlock()
+		return p
+	}
+
+	p := factory()
+	r.promises[key] = p
+
+	alwaysAfterUnlock(&r.lock, p, func(p2 Controller) {
+		if p2.IsSuccess() {
+			return
+		}
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		// only remove the entry if it still refers to this promise
+		if r.promises[key] == p {
+			delete(r.promises, key)
+		}
+	})
+
+	return p
+}
+
+// Reset removes the promise for key, so that the next call to Once for key
+// invokes its factory
+func (r *OnceRegistry) Reset(key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.promises, key)
+}
+
+// Once uses a process-wide registry to invoke factory once for key
+//
+//	Notes
+//		See OnceRegistry.Once
+//
+func Once(key string, factory Factory) Promise {
+	return once.Once(key, factory)
+}
+
+// ResetOnce removes the promise for key from the process-wide registry
+// used by Once
+//
+//	Notes
+//		This is primarily intended for tests
+//
+func ResetOnce(key string) {
+	once.Reset(key)
+}
@@ -0,0 +1,189 @@
+package promise
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Executor runs functions on behalf of a promise
+//
+//	Notes
+//		A promise created with WithExecutor notifies its handlers (on
+//		delivery) via Execute, rather than from the delivering goroutine,
+//		e.g. to run the handlers on a worker pool
+//
+type Executor interface {
+	Execute(fn func())
+}
+
+// PromiseOption configures a promise created by NewPromiseWithOptions
+type PromiseOption func(*promiseConfig)
+
+// promiseConfig is the configuration built from the PromiseOptions
+type promiseConfig struct {
+	id          string
+	logger      *slog.Logger
+	executor    Executor
+	timeout     time.Duration
+	deadline    time.Time
+	ctx         context.Context
+	maxHandlers int
+	order       HandlerOrder
+}
+
+// promiseExt holds the optional configuration of a promise, so that
+// promises created without options do not carry it
+type promiseExt struct {
+	logger   *slog.Logger
+	executor Executor
+
+	// ctx is the creation context, which is passed to context handlers
+	// (see SuccessCtx)
+	ctx context.Context
+}
+
+// WithID sets the correlation id of the promise (see Correlate)
+func WithID(id string) PromiseOption {
+	return func(c *promiseConfig) { c.id = id }
+}
+
+// WithLogger sets the logger used for the log messages of the promise
+// (handler panics, double deliveries), instead of the standard logger
+func WithLogger(l *slog.Logger) PromiseOption {
+	return func(c *promiseConfig) { c.logger = l }
+}
+
+// WithExecutor sets the Executor used to notify the handlers of the
+// promise when it is delivered
+func WithExecutor(e Executor) PromiseOption {
+	return func(c *promiseConfig) { c.executor = e }
+}
+
+// WithTimeout fails the promise with ErrPromiseTimeout if it is not
+// delivered within d, instead of the default timeout
+func WithTimeout(d time.Duration) PromiseOption {
+	return func(c *promiseConfig) { c.timeout = d }
+}
+
+// WithDeadline fails the promise with ErrPromiseTimeout if it is not
+// delivered by t, instead of the default timeout
+func WithDeadline(t time.Time) PromiseOption {
+	return func(c *promiseConfig) { c.deadline = t }
+}
+
+// WithCancelOnContext cancels the promise when ctx is done
+func WithCancelOnContext(ctx context.Context) PromiseOption {
+	return func(c *promiseConfig) { c.ctx = ctx }
+}
+
+// WithMaxHandlers limits the number of handlers registered via the
+// returned Controller (see Controller.WithMaxHandlers)
+func WithMaxHandlers(n int) PromiseOption {
+	return func(c *promiseConfig) { c.maxHandlers = n }
+}
+
+// WithHandlerOrder sets the order in which the handlers of the promise are
+// invoked (see Controller.WithHandlerOrder)
+func WithHandlerOrder(order HandlerOrder) PromiseOption {
+	return func(c *promiseConfig) { c.order = order }
+}
+
+// NewPromiseWithOptions creates a promise configured by opts
+//
+//	Notes
+//		The default timeout (see SetDefaultTimeout) is applied unless
+//		WithTimeout or WithDeadline is specified. If both are specified,
+//		the promise fails at whichever expires first
+//
+//		With no options, NewPromiseWithOptions is equivalent to NewPromise
+//
+func NewPromiseWithOptions(opts ...PromiseOption) Controller {
+	var config promiseConfig
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	p := &promise{}
+
+	if config.logger != nil || config.executor != nil || config.ctx != nil {
+		p.ext = &promiseExt{logger: config.logger, executor: config.executor, ctx: config.ctx}
+	}
+
+	if config.id != "" {
+		p.setCorrelationID(config.id)
+	}
+
+	p.order.Store(int32(config.order))
+
+	switch {
+	case config.timeout > 0 || !config.deadline.IsZero():
+		if config.timeout > 0 {
+			p.expireAfter(config.timeout)
+		}
+
+		if !config.deadline.IsZero() {
+			p.expireAfter(time.Until(config.deadline))
+		}
+	default:
+		p.applyDefaultTimeout()
+	}
+
+	if config.ctx != nil {
+		p.cancelOnContext(config.ctx)
+	}
+
+	if config.maxHandlers > 0 {
+		return p.WithMaxHandlers(config.maxHandlers)
+	}
+
+	return p
+}
+
+// NewPromiseWithContext creates a promise that is canceled when ctx is done
+//
+//	Notes
+//		If ctx is already done, the promise is returned already canceled.
+//		Otherwise ctx is monitored via context.AfterFunc, so no goroutine is
+//		started until ctx is done, and the monitoring stops when the promise
+//		is delivered
+//
+//		ctx is also passed to the context handlers of the promise (see
+//		SuccessCtx)
+//
+//		Equivalent to NewPromiseWithOptions(WithCancelOnContext(ctx))
+//
+func NewPromiseWithContext(ctx context.Context) Controller {
+	p := newPromise()
+
+	p.ext = &promiseExt{ctx: ctx}
+
+	p.applyDefaultTimeout()
+
+	p.cancelOnContext(ctx)
+
+	return p
+}
+
+// cancelOnContext cancels the promise when ctx is done
+//
+//	Notes
+//		If ctx is already done, the promise is canceled immediately.
+//		Otherwise no goroutine is used to monitor ctx, and the monitoring
+//		stops when the promise is delivered
+//
+func (p *promise) cancelOnContext(ctx context.Context) {
+	if ctx.Err() != nil {
+		p.tryDeliver(ErrPromiseCanceled)
+		return
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		p.tryDeliver(ErrPromiseCanceled)
+	})
+
+	p.Always(func(Controller) {
+		stop()
+	})
+}
@@ -0,0 +1,116 @@
+package promise
+
+import "context"
+
+// priorityController is a view of a promise that registers handlers with a
+// priority
+//
+//	Notes
+//		The priority applies to the handlers registered via Success, Catch,
+//		Canceled, Always and their *Ctx forms. The chaining methods (Then,
+//		Thenf, etc.) and Wait register their handlers at the default
+//		priority, as they continue the chain rather than handle the result
+//
+type priorityController struct {
+	*promise
+	priority int
+}
+
+var _ Controller = &priorityController{}
+
+// WithPriority returns a Controller for this promise that registers
+// handlers with priority
+func (p *promise) WithPriority(priority int) Controller {
+	return &priorityController{promise: p, priority: priority}
+}
+
+// WithPriority returns a Controller for the promise with a new priority
+func (p *priorityController) WithPriority(priority int) Controller {
+	return p.promise.WithPriority(priority)
+}
+
+// Success registers a callback on successful delivery of the promise
+func (p *priorityController) Success(handler SuccessHandler) Promise {
+	p.promise.SuccessPriority(p.priority, handler)
+
+	return p
+}
+
+// Catch registers a callback on a failed delivery of the promise
+func (p *priorityController) Catch(handler CatchHandler) Promise {
+	p.countHandler("Catch")
+
+	if !p.catchHandlers.addPriority(p.priority, handler) && p.IsError() {
+		handler(p.Error())
+	}
+
+	return p
+}
+
+// Canceled registers a callback for the case where the promise delivery
+// is canceled
+func (p *priorityController) Canceled(handler CanceledHandler) Promise {
+	p.countHandler("Canceled")
+
+	if !p.canceledHandlers.addPriority(p.priority, handler) && p.IsCanceled() {
+		handler()
+	}
+
+	return p
+}
+
+// Always registers a callback when the promise is delivered or canceled
+func (p *priorityController) Always(handler AlwaysHandler) Promise {
+	p.countHandler("Always")
+
+	if !p.alwaysHandlers.addPriority(p.priority, handler) && p.IsDelivered() {
+		handler(p.promise)
+	}
+
+	return p
+}
+
+// SuccessCtx registers a callback on successful delivery of the promise
+// that receives ctx
+func (p *priorityController) SuccessCtx(ctx context.Context, handler SuccessHandlerWithContext) Promise {
+	ctx = p.handlerContext(ctx)
+
+	return p.Success(func(result interface{}) {
+		handler(ctx, result)
+	})
+}
+
+// CatchCtx registers a callback on a failed delivery of the promise that
+// receives ctx
+func (p *priorityController) CatchCtx(ctx context.Context, handler CatchHandlerWithContext) Promise {
+	ctx = p.handlerContext(ctx)
+
+	return p.Catch(func(err error) {
+		handler(ctx, err)
+	})
+}
+
+// AlwaysCtx registers a callback when the promise is delivered or canceled
+// that receives ctx
+func (p *priorityController) AlwaysCtx(ctx context.Context, handler AlwaysHandlerWithContext) Promise {
+	ctx = p.handlerContext(ctx)
+
+	return p.Always(func(p2 Controller) {
+		handler(ctx, p2)
+	})
+}
+
+// WithMaxHandlers sets the handler limit of the promise
+func (p *priorityController) WithMaxHandlers(n int) Controller {
+	p.promise.WithMaxHandlers(n)
+
+	return p
+}
+
+// WithHandlerOrder sets the order in which the handlers of the promise are
+// invoked
+func (p *priorityController) WithHandlerOrder(order HandlerOrder) Controller {
+	p.promise.WithHandlerOrder(order)
+
+	return p
+}
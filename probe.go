@@ -0,0 +1,108 @@
+package promise
+
+import (
+	"sync"
+	"time"
+)
+
+// minProbeInterval is the interval used for polling when the specified
+// interval is not positive
+const minProbeInterval = time.Millisecond
+
+// Probe creates a promise that is delivered by polling
+//
+//	Notes
+//		Every interval, fn is invoked with the promise. When fn returns true
+//		the promise is delivered successfully with a value of true, unless
+//		fn has already delivered the promise itself
+//
+//		No goroutine is blocked between polls, and polling stops as soon as
+//		the promise is delivered (including when it is canceled)
+//
+//		An interval that is not positive is clamped to a millisecond, so
+//		polling never spins
+//
+func Probe(interval time.Duration, fn func(Promise) bool) Promise {
+	p := newPromise()
+
+	p.poll(interval, func() bool {
+		if fn(p) {
+			p.tryDeliver(true)
+			return true
+		}
+
+		return false
+	})
+
+	return p
+}
+
+// ProbeWithResult creates a promise that is delivered by polling
+//
+//	Notes
+//		Every interval, fn is invoked to fetch and check a result. If fn
+//		returns an error the promise fails with the error. If fn returns
+//		true the promise succeeds with the result from fn. Otherwise polling
+//		continues
+//
+//		As with Probe, an interval that is not positive is clamped to a
+//		millisecond
+//
+func ProbeWithResult(interval time.Duration, fn func() (interface{}, bool, error)) Promise {
+	p := newPromise()
+
+	p.poll(interval, func() bool {
+		result, done, err := fn()
+
+		switch {
+		case err != nil:
+			p.tryDeliver(err)
+		case done:
+			p.tryDeliver(result)
+		default:
+			return false
+		}
+
+		return true
+	})
+
+	return p
+}
+
+// poll invokes check every interval until check returns true or the
+// promise is delivered
+func (p *promise) poll(interval time.Duration, check func() bool) {
+	if interval <= 0 {
+		interval = minProbeInterval
+	}
+
+	var lock sync.Mutex
+	var timer *time.Timer
+	var tick func()
+
+	tick = func() {
+		if p.IsDelivered() || check() {
+			return
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		// schedule the next poll, unless the promise got delivered
+		if p.IsPending() {
+			timer = time.AfterFunc(interval, tick)
+		}
+	}
+
+	lock.Lock()
+	timer = time.AfterFunc(interval, tick)
+	lock.Unlock()
+
+	// stop polling once the promise is delivered
+	p.Always(func(Controller) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		timer.Stop()
+	})
+}
@@ -1,5 +1,10 @@
 package promise
 
+import (
+	"context"
+	"time"
+)
+
 // SuccessHandler is the function prototype for promise listeners that
 // receive the results of a successful delivery of the promise
 type SuccessHandler func(result interface{})
@@ -16,6 +21,20 @@ type CanceledHandler func()
 // receive a callback regardless of the result of the promise deliver
 type AlwaysHandler func(promise Controller)
 
+// SuccessHandlerWithContext is the function prototype for promise listeners
+// that receive a context and the results of a successful delivery of the
+// promise
+type SuccessHandlerWithContext func(ctx context.Context, result interface{})
+
+// CatchHandlerWithContext is the function prototype for promise listeners
+// that receive a context and the error from an unsuccessful promise delivery
+type CatchHandlerWithContext func(ctx context.Context, err error)
+
+// AlwaysHandlerWithContext is the function prototype for promise listeners
+// that receive a context and a callback regardless of the result of the
+// promise delivery
+type AlwaysHandlerWithContext func(ctx context.Context, promise Controller)
+
 // Factory is a function prototype that returns a Promise
 type Factory func() Promise
 
@@ -23,6 +42,17 @@ type Factory func() Promise
 // that creates another promise
 type FactoryWithResult func(result interface{}) Promise
 
+// AllResults is the result of ThenAllWithResultsAndErrors
+//
+//	Notes
+//		Values[i] is the successful result of promise i (nil if it failed),
+//		and Errors[i] is the error of promise i (nil if it succeeded)
+//
+type AllResults struct {
+	Values []interface{}
+	Errors []error
+}
+
 // Promise is the interface for Promise delivery
 type Promise interface {
 	// Success registers a callback on successful delivery of the promise
@@ -38,6 +68,34 @@ type Promise interface {
 	// Always registers a callback when the promise is delivered or canceled
 	Always(handler AlwaysHandler) Promise
 
+	// SuccessCtx registers a callback on successful delivery of the promise
+	// that receives ctx
+	//
+	//	Notes
+	//		The context is passed to the handler for its own use (trace ids,
+	//		deadlines, etc.) and does not affect the delivery of the promise.
+	//		If the promise was created with a context (NewPromiseWithContext
+	//		or WithCancelOnContext), that context is passed to the handler.
+	//		Otherwise ctx is passed, or context.Background() if ctx is nil
+	//
+	SuccessCtx(ctx context.Context, handler SuccessHandlerWithContext) Promise
+
+	// CatchCtx registers a callback on a failed delivery of the promise that
+	// receives ctx
+	//
+	//	Notes
+	//		See SuccessCtx
+	//
+	CatchCtx(ctx context.Context, handler CatchHandlerWithContext) Promise
+
+	// AlwaysCtx registers a callback when the promise is delivered or
+	// canceled that receives ctx
+	//
+	//	Notes
+	//		See SuccessCtx
+	//
+	AlwaysCtx(ctx context.Context, handler AlwaysHandlerWithContext) Promise
+
 	// Allows a wait on promise delivery via a channel
 	//
 	//  Notes
@@ -113,10 +171,13 @@ type Promise interface {
 	//
 	//	Notes
 	//		the result of the returned promise, if successful, will be
-	//		the result of the first promise that completes. This is clearly
+	//		the result of the first promise that succeeds. This is clearly
 	//		non-deterministic, but IFF the promises delivery results are
 	//		homogenous then the result type will be deterministic.
 	//
+	//		The returned promise fails only if all of the promises fail, with
+	//		the error of the last failure (see Any)
+	//
 	ThenAny(promises ...Promise) Promise
 
 	// Chain a promise to successful delivery of any one from a list of Promises
@@ -124,9 +185,496 @@ type Promise interface {
 	//
 	//	Notes
 	//		the result of the returned promise, if successful, will be
-	//		the result of the first promise that completes. This is clearly
+	//		the result of the first promise that succeeds. This is clearly
 	//		non-deterministic, but IFF the promises delivery results are
 	//		homogenous then the result type will be deterministic.
 	//
+	//		The returned promise fails only if all of the promises fail, with
+	//		the error of the last failure (see Any)
+	//
 	ThenAnyf(factories func() []Promise) Promise
+
+	// Map2 combines the results of this promise and another promise using
+	// a transform
+	//
+	//	Notes
+	//		fn is invoked with the results of both promises once both have
+	//		succeeded, and the returned promise is delivered with the result
+	//		of fn, or fails with the error from fn. If either promise fails,
+	//		the returned promise fails with that error
+	//
+	Map2(other Promise, fn func(a, b interface{}) (interface{}, error)) Promise
+
+	// ThenAllWithResultsAndErrors chains a list of Promises to the successful
+	// delivery of this Promise, and waits for all of them to be delivered
+	//
+	//	Notes
+	//		Unlike ThenAll, a failed promise does not fail the returned promise.
+	//		If this promise succeeds, the returned promise always succeeds with
+	//		an AllResults containing the result and error of each promise, in
+	//		the same order as promises
+	//
+	ThenAllWithResultsAndErrors(promises ...Promise) Promise
+
+	// ThenAllSettled chains a list of Promises to the successful delivery of
+	// this Promise, and waits for all of them to be delivered
+	//
+	//	Notes
+	//		This is the equivalent of Promise.allSettled for JavaScript. If
+	//		this promise succeeds, the returned promise always succeeds once
+	//		every one of the promises has been delivered, with a
+	//		[]PromiseSettledResult holding the outcome of each promise, in the
+	//		same order as promises
+	//
+	ThenAllSettled(promises ...Promise) Promise
+
+	// ThenN chains a list of Promises to the successful delivery of this
+	// Promise, and succeeds once n of them have succeeded
+	//
+	//	Notes
+	//		If successful, the result of the returned promise is a
+	//		[]interface{} of the first n results, in order of delivery. The
+	//		returned promise fails as soon as n successes are no longer
+	//		possible (see WhenN)
+	//
+	ThenN(n int, promises ...Promise) Promise
+
+	// CatchChain chains a Promise (created via fn) to the failed delivery of
+	// this Promise, allowing recovery from an error
+	//
+	//	Notes
+	//		Unlike Catch, which returns this promise, CatchChain returns a new
+	//		promise. If this promise fails (or is canceled), fn is invoked with
+	//		the error and the returned promise is delivered with the result of
+	//		the promise from fn. If this promise succeeds, the returned promise
+	//		is delivered with the same result
+	//
+	CatchChain(fn func(err error) Promise) Promise
+
+	// ThenAllLazy chains a list of Promises (created on demand via Factory)
+	// to the successful delivery of this Promise
+	//
+	//	Notes
+	//		No factory is invoked until this promise succeeds, and at most
+	//		maxConcurrent of the promises are in-flight at any time (a value
+	//		<= 0 is treated as 1). The next factory is invoked only when an
+	//		in-flight promise succeeds
+	//
+	//		If successful, the result of the returned promise is []interface{}
+	//		with the result of each promise, in the same order as factories.
+	//		The returned promise fails on the first failure, and no further
+	//		factories are invoked
+	//
+	ThenAllLazy(maxConcurrent int, factories ...Factory) Promise
+
+	// ThenWithResultAll chains the result of a successful promise to a list
+	// of Promises created from the result
+	//
+	//	Notes
+	//		factory is invoked with the result of this promise, and the returned
+	//		promise succeeds when all of the promises from factory succeed (see
+	//		ThenAll), or fails on the first failure
+	//
+	ThenWithResultAll(factory func(result interface{}) []Promise) Promise
+
+	// ThenWithResultAny chains the result of a successful promise to a list
+	// of Promises created from the result
+	//
+	//	Notes
+	//		factory is invoked with the result of this promise, and the returned
+	//		promise succeeds with the result of the first of the promises from
+	//		factory to succeed. The returned promise fails only if all of the
+	//		promises fail, with the error of the last failure
+	//
+	ThenWithResultAny(factory func(result interface{}) []Promise) Promise
+
+	// OrderedThenAll chains a list of Promises to the successful delivery of
+	// this Promise, where the promises are considered in order
+	//
+	//	Notes
+	//		promises[i+1] is only considered once promises[i] has succeeded, and
+	//		the returned promise fails with the first failure in list order. If
+	//		successful, the result of the returned promise is the result of the
+	//		last promise in the list
+	//
+	//		The promises are already created (and running) when they are
+	//		passed to OrderedThenAll. To create each promise only after the
+	//		previous one succeeds, use ThenAllLazy with maxConcurrent == 1
+	//
+	OrderedThenAll(promises ...Promise) Promise
+
+	// ThenWithRecovery chains a Promise to the delivery of this Promise,
+	// using factory on success or recovery on failure
+	//
+	//	Notes
+	//		This is the equivalent of then(onFulfilled, onRejected) for a
+	//		JavaScript promise. If this promise succeeds, factory is invoked
+	//		with the result; if it fails (or is canceled), recovery is invoked
+	//		with the error. Either way, the returned promise is delivered with
+	//		the result of the promise that was chained
+	//
+	//		Unlike ThenWithResult(factory).CatchChain(recovery), recovery is
+	//		only invoked for the failure of this promise, and not for a
+	//		failure of the promise from factory
+	//
+	ThenWithRecovery(factory FactoryWithResult, recovery func(err error) Promise) Promise
+
+	// ThenAnyWithResult chains the first success from a list of Promises to
+	// the successful delivery of this Promise
+	//
+	//	Notes
+	//		If successful, the result of the returned promise is the result of
+	//		the first of the promises to succeed. A failure does not fail the
+	//		returned promise unless all of the promises fail, in which case it
+	//		fails with the error of the last failure
+	//
+	ThenAnyWithResult(promises ...Promise) Promise
+
+	// ThenAnyWithResultf chains the first success from a list of Promises
+	// (created via factory) to the successful delivery of this Promise
+	//
+	//	Notes
+	//		factory is only invoked if this promise succeeds. The returned
+	//		promise succeeds with the result of the first of the promises from
+	//		factory to succeed, and fails only if all of the promises fail,
+	//		with the error of the last failure
+	//
+	ThenAnyWithResultf(factory func() []Promise) Promise
+
+	// ToFuture returns a Future backed by this Promise
+	//
+	//	Notes
+	//		Get blocks until this promise is delivered, so the Future can be
+	//		passed to code that expects future style blocking results
+	//
+	ToFuture() Future
+
+	// ThenAllWithResults chains a list of Promises to the successful
+	// delivery of this Promise, and collects their results
+	//
+	//	Notes
+	//		If successful, the result of the returned promise is a
+	//		[]interface{} of the results, in the same order as promises
+	//		(regardless of the order in which they are delivered)
+	//
+	//		As with ThenAll, the returned promise fails on the first failure.
+	//		To also cancel the promises that are still pending, use
+	//		ThenAllWithResultsCanceled
+	//
+	ThenAllWithResults(promises ...Promise) Promise
+
+	// ThenAllWithResultsCanceled chains a list of Promises to the successful
+	// delivery of this Promise, and collects their results
+	//
+	//	Notes
+	//		If successful, the result of the returned promise is a
+	//		[]interface{} of the results, in the same order as promises
+	//
+	//		The returned promise fails on the first failure, and any of the
+	//		promises that are still pending are canceled, so that in-flight
+	//		work can be cleaned up
+	//
+	ThenAllWithResultsCanceled(promises ...Promise) Promise
+
+	// ThenPair chains a Promise (created via factory from the results of
+	// this promise and another promise) to the successful delivery of both
+	//
+	//	Notes
+	//		This is the asynchronous form of Map2, where factory returns a
+	//		promise rather than a value. The returned promise fails if either
+	//		promise fails, otherwise it is delivered with the result of the
+	//		promise from factory
+	//
+	ThenPair(other Promise, factory func(a, b interface{}) Promise) Promise
+
+	// ThenAllWithResultsMap chains a list of Promises to the successful
+	// delivery of this Promise, and collects their results by key
+	//
+	//	Notes
+	//		If successful, the result of the returned promise is a
+	//		map[string]interface{}, where keyFn returns the key for the
+	//		result of promises[i]. If more than one result has the same key,
+	//		the result with the highest index is kept
+	//
+	//		The returned promise fails on the first failure
+	//
+	ThenAllWithResultsMap(keyFn func(i int, result interface{}) string, promises ...Promise) Promise
+
+	// ThenChain chains a sequence of Promises (created via factories) to the
+	// successful delivery of this Promise
+	//
+	//	Notes
+	//		factories[0] is invoked with the result of this promise,
+	//		factories[1] with the result of the promise from factories[0], and
+	//		so on. The returned promise is delivered with the result of the
+	//		last promise, or fails on the first failure in the chain
+	//
+	//		With no factories, the returned promise mirrors this promise
+	//
+	ThenChain(factories ...FactoryWithResult) Promise
+
+	// ThenWithResultMap chains the result of a successful promise to a named
+	// set of Promises created from the result
+	//
+	//	Notes
+	//		factory is invoked with the result of this promise. If successful,
+	//		the result of the returned promise is a map[string]interface{}
+	//		with the result of each promise from factory, by the same key. The
+	//		returned promise fails on the first failure
+	//
+	ThenWithResultMap(factory func(result interface{}) map[string]Promise) Promise
+
+	// ThenWithTimeout chains the result of a successful promise to another
+	// promise, which must be delivered within d
+	//
+	//	Notes
+	//		The returned promise fails with ErrPromiseTimeout if the promise
+	//		from factory is not delivered within d of factory being invoked.
+	//		The promise from factory is not canceled, as only the chain stops
+	//		waiting for it
+	//
+	ThenWithTimeout(d time.Duration, factory FactoryWithResult) Promise
+
+	// ThenfWithTimeout chains a successful promise to another promise, which
+	// must be delivered within d
+	//
+	//	Notes
+	//		ThenfWithTimeout is to Thenf what ThenWithTimeout is to
+	//		ThenWithResult. If this promise fails, the failure is propagated
+	//		and factory is not invoked, so no timer is started. Otherwise the
+	//		timer starts when factory is invoked
+	//
+	ThenfWithTimeout(d time.Duration, factory Factory) Promise
+
+	// IgnoreErrors returns a promise that succeeds with a nil result if this
+	// promise fails with one of the target errors
+	//
+	//	Notes
+	//		The error is matched against the targets via errors.Is. Any other
+	//		failure, and any success, is delivered unchanged
+	//
+	IgnoreErrors(targets ...error) Promise
+
+	// IgnoreErrorTypes returns a promise that succeeds with a nil result if
+	// this promise fails with an error of one of the types
+	//
+	//	Notes
+	//		The error is matched via errors.As, so each type is specified the
+	//		same way as the target of errors.As, e.g. new(*os.PathError), or
+	//		as a value of the type, e.g. (*os.PathError)(nil). Any other
+	//		failure, and any success, is delivered unchanged
+	//
+	//		IgnoreErrorTypes panics if one of the types is not an error type
+	//
+	IgnoreErrorTypes(types ...interface{}) Promise
+
+	// ThenSwitchAll chains the result of a successful promise to a Promise
+	// created by the factory selected for the result
+	//
+	//	Notes
+	//		selector is invoked with the result of this promise to choose the
+	//		key of the factory in cases, which is then invoked with the result.
+	//		If there is no factory for the key, defaultCase is invoked, or if
+	//		defaultCase is nil the returned promise fails with
+	//		ErrNoMatchingCase
+	//
+	//		The result of the promise from the selected factory is then passed
+	//		to merge, which is shared by all of the cases, and the promises it
+	//		creates are chained as with ThenAllWithResults. So each case can
+	//		route to specialized handling, and the results are then merged by
+	//		common promises. If successful, the result of the returned promise
+	//		is a []interface{} of the results of the merge promises, in the
+	//		same order as they are created
+	//
+	//		If merge is nil, the returned promise is delivered with the result
+	//		of the promise from the selected factory
+	//
+	ThenSwitchAll(selector func(result interface{}) string, cases map[string]FactoryWithResult, defaultCase FactoryWithResult, merge func(result interface{}) []Promise) Promise
+
+	// SuccessPriority registers a callback, with a priority, on successful
+	// delivery of the promise
+	//
+	//	Notes
+	//		Handlers with a higher priority are invoked first, and handlers
+	//		with the same priority are invoked in the HandlerOrder of the
+	//		promise. Success registers a handler with a priority of 0, so a
+	//		negative priority is invoked after those handlers
+	//
+	SuccessPriority(priority int, handler SuccessHandler) Promise
+
+	// MapError returns a promise that transforms the error of a failed
+	// delivery of this promise via fn
+	//
+	//	Notes
+	//		A successful delivery is passed through unchanged, and so is a
+	//		cancellation, so that ErrPromiseCanceled is not transformed. If fn
+	//		returns nil, the original error is delivered
+	//
+	//		The transformed error wraps the original error, so errors.Is and
+	//		errors.As match both the transformed and the original error
+	//
+	MapError(fn func(err error) error) Promise
+
+	// ThenWithResultCatch chains a Promise to the delivery of this Promise,
+	// using factory on success or catch on failure
+	//
+	//	Notes
+	//		ThenWithResultCatch is equivalent to ThenWithRecovery, and is named
+	//		for symmetry with ThenWithResult and CatchChain
+	//
+	ThenWithResultCatch(factory FactoryWithResult, catch func(err error) Promise) Promise
+
+	// ConvertError returns a promise that fails with to if this promise
+	// fails with from, and otherwise mirrors this promise
+	//
+	//	Notes
+	//		The error is matched against from via errors.Is, so a wrapped
+	//		from is also converted. This translates infrastructure errors
+	//		(e.g. sql.ErrNoRows) into domain errors
+	//
+	ConvertError(from, to error) Promise
+
+	// ConvertErrors returns a promise that fails with mapping[from] if this
+	// promise fails with an error matching from, and otherwise mirrors
+	// this promise
+	//
+	//	Notes
+	//		See ConvertError. The mapping should not contain more than one
+	//		key matching the same error, as the order in which the keys are
+	//		considered is undefined
+	//
+	ConvertErrors(mapping map[error]error) Promise
+
+	// ThenWithResultRetry chains the result of a successful promise to
+	// another promise, retrying up to n times if it fails
+	//
+	//	Notes
+	//		factory is invoked with the same result of this promise for each
+	//		attempt, and the retries are made without a delay. The returned
+	//		promise fails with the error of the last attempt. A canceled
+	//		attempt is not retried (see NewRetryWithPolicy)
+	//
+	ThenWithResultRetry(n int, factory FactoryWithResult) Promise
+
+	// ThenWithResultWindow chains windows of the result of a successful
+	// promise to Promises (created via factory) that run concurrently
+	//
+	//	Notes
+	//		The result of this promise must be a []interface{} (for example,
+	//		from ThenAllWithResultsCanceled), otherwise the returned promise
+	//		fails with ErrResultNotSlice. The result is split into chunks of
+	//		windowSize, and factory is invoked with each chunk (as a
+	//		[]interface{})
+	//
+	//		If successful, the result of the returned promise is a
+	//		[]interface{} of the result for each chunk, in order. The returned
+	//		promise fails on the first failure
+	//
+	ThenWithResultWindow(windowSize int, factory FactoryWithResult) Promise
+
+	// ThenRetryWithBackoff chains the result of a successful promise to
+	// another promise, retrying with exponential backoff if it fails
+	//
+	//	Notes
+	//		factory is invoked with the same result of this promise for each
+	//		of at most maxAttempts attempts. The delay before the first retry
+	//		is initial, and the delay is multiplied by multiplier for each
+	//		subsequent retry. The returned promise fails with the error of the
+	//		last attempt
+	//
+	//		Canceling the returned promise stops any further retries. If this
+	//		promise was created with a context (see NewPromiseWithContext),
+	//		the returned promise is also canceled when the context is done
+	//
+	ThenRetryWithBackoff(maxAttempts int, initial time.Duration, multiplier float64, factory FactoryWithResult) Promise
+
+	// ThenWithResultOrTimeout chains the result of a successful promise to
+	// another promise, which is raced against a timeout promise
+	//
+	//	Notes
+	//		If timeout is delivered (successfully or not) before the promise
+	//		from factory, the returned promise fails with ErrPromiseTimeout,
+	//		otherwise it is delivered with the result of the promise from
+	//		factory. The promise from factory is not canceled
+	//
+	//		timeout is typically DeliverTimeout(d, ErrPromiseTimeout), but can
+	//		be any promise (e.g. a Controller delivered by a test). If timeout
+	//		is nil, a timeout of d from the invocation of factory is used
+	//
+	ThenWithResultOrTimeout(d time.Duration, factory FactoryWithResult, timeout Promise) Promise
+
+	// ThenWithResultConcurrent chains each element of the result of a
+	// successful promise to a Promise (created via factory), with at most
+	// concurrency promises in-flight
+	//
+	//	Notes
+	//		The result of this promise must be a []interface{}, otherwise the
+	//		returned promise fails with ErrResultNotSlice. If successful, the
+	//		result of the returned promise is a []interface{} of the result
+	//		for each element, in the same order as the elements. The returned
+	//		promise fails on the first failure, and no further factories are
+	//		invoked
+	//
+	ThenWithResultConcurrent(concurrency int, factory FactoryWithResult) Promise
+
+	// Checkpoint registers a named checkpoint that invokes fn when this
+	// promise is delivered, and returns this promise
+	//
+	//	Notes
+	//		fn is invoked with name and the delivered promise, for auditing
+	//		the stages of a chain. The hook set via GlobalCheckpointHook (if
+	//		any) is also invoked for each checkpoint. Any number of
+	//		checkpoints can be registered on the same promise
+	//
+	Checkpoint(name string, fn func(name string, p Controller)) Promise
+
+	// WaitContext blocks until this promise is delivered, or ctx is done
+	//
+	//	Notes
+	//		Returns the delivered promise and nil, or nil and ctx.Err() if ctx
+	//		is done first. No channel is required from the caller, and an
+	//		already delivered promise returns immediately
+	//
+	WaitContext(ctx context.Context) (Promise, error)
+
+	// WaitTimeout blocks until this promise is delivered, for at most d
+	//
+	//	Notes
+	//		Returns the delivered promise and true, or nil and false if the
+	//		promise is not delivered within d. If d <= 0, the delivery is
+	//		checked without blocking
+	//
+	WaitTimeout(d time.Duration) (Promise, bool)
+
+	// Timeout returns a promise that is delivered with the result of this
+	// promise, or fails with ErrPromiseTimeout if this promise is not
+	// delivered within d
+	//
+	//	Notes
+	//		If this promise is canceled before d, the returned promise is also
+	//		canceled. The timer is stopped as soon as the returned promise is
+	//		delivered. This promise is not affected by the timeout
+	//
+	Timeout(d time.Duration) Promise
+
+	// Deadline returns a promise that is delivered with the result of this
+	// promise, or fails with ErrPromiseTimeout if this promise is not
+	// delivered by t
+	//
+	//	Notes
+	//		If t is not in the future, the returned promise fails immediately
+	//		and no timer is started. See Timeout
+	//
+	Deadline(t time.Time) Promise
+
+	// CancelAfter cancels this promise if it is still pending after d, and
+	// returns this promise
+	//
+	//	Notes
+	//		Unlike Timeout, which fails a new promise when the caller gives up
+	//		waiting, CancelAfter aborts this promise itself, so its Canceled
+	//		handlers are invoked. The timer is stopped when this promise is
+	//		delivered
+	//
+	CancelAfter(d time.Duration) Promise
 }
@@ -0,0 +1,127 @@
+package promise
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func BenchmarkDeliver(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		NewPromise().SucceedWithResult(i)
+	}
+}
+
+func BenchmarkSuccessHandler(b *testing.B) {
+	for _, handlers := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("handlers=%d", handlers), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				p := NewPromise()
+
+				for j := 0; j < handlers; j++ {
+					p.Success(func(result interface{}) {})
+				}
+
+				p.SucceedWithResult(i)
+			}
+		})
+	}
+}
+
+func BenchmarkCatchHandler(b *testing.B) {
+	err := fmt.Errorf("benchmark")
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		NewPromise().Catch(func(err error) {}).(Controller).Fail(err)
+	}
+}
+
+func BenchmarkAlwaysHandler(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		NewPromise().Always(func(p Controller) {}).(Controller).Succeed()
+	}
+}
+
+func BenchmarkThenAllN(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				promises := make([]Promise, n)
+				for j := range promises {
+					promises[j] = NewPromise()
+				}
+
+				root := NewPromise()
+				root.ThenAll(promises...)
+				root.Succeed()
+
+				for _, p := range promises {
+					p.(Controller).Succeed()
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRace measures the first-delivery-wins path (Race)
+func BenchmarkRace(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		p1 := NewPromise()
+		p2 := NewPromise()
+
+		Race(p1, p2)
+
+		p1.Succeed()
+	}
+}
+
+// BenchmarkParallelDelivery delivers promises from GOMAXPROCS goroutines
+// simultaneously, where each goroutine delivers to its own promises
+func BenchmarkParallelDelivery(b *testing.B) {
+	procs := runtime.GOMAXPROCS(0)
+
+	b.ReportAllocs()
+
+	var wg sync.WaitGroup
+
+	wg.Add(procs)
+
+	for g := 0; g < procs; g++ {
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < b.N/procs; i++ {
+				NewPromise().Success(func(result interface{}) {}).(Controller).SucceedWithResult(i)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkWaitChan(b *testing.B) {
+	waitChan := make(chan Controller, 1)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		p := NewPromise()
+
+		go p.Succeed()
+
+		p.Wait(waitChan)
+	}
+}
@@ -1,40 +1,204 @@
 package promise
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"math"
+	"reflect"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // promise implements Controller and Promise
 type promise struct {
-	// lock is used to protect use of handler arrays, and delivery
-	lock             sync.Mutex
-	successHandlers  []SuccessHandler
-	catchHandlers    []CatchHandler
-	alwaysHandlers   []AlwaysHandler
-	canceledHandlers []CanceledHandler
+	// lock is used to serialize delivery
+	lock sync.Mutex
 
-	// the result of the promise as an atomic value
-	result atomic.Value
+	// handlers are kept in lock-free lists that are closed on delivery
+	successHandlers  handlerList[SuccessHandler]
+	catchHandlers    handlerList[CatchHandler]
+	alwaysHandlers   handlerList[AlwaysHandler]
+	canceledHandlers handlerList[CanceledHandler]
+
+	// order is the HandlerOrder used to invoke the handlers
+	order atomic.Int32
+
+	// correlationID is included in log messages (see Correlate)
+	correlationID atomic.Pointer[string]
+
+	// ext is the optional configuration (see NewPromiseWithOptions)
+	ext *promiseExt
+
+	// limit enforces WithMaxHandlers, and is empty unless built with the
+	// promise_debug build tag
+	limit handlerLimit
+
+	// the result of the promise, which is nil until the promise is delivered
+	result atomic.Pointer[deliveryResult]
+
+	// delivery is the storage for result, and is written exactly once (under
+	// lock) before result is stored, so delivery does not allocate
+	delivery deliveryResult
 }
 
 var _ Controller = &promise{}
 
-// use internally when delivery is called with value == nil
-// to allow nil to be a delivered value
-var nilResult = &struct{}{}
+// deliveryKind identifies how a promise was delivered
+type deliveryKind int
+
+const (
+	deliveredSuccess deliveryKind = iota
+	deliveredError
+	deliveredCanceled
+)
+
+// deliveryResult is the delivered result (or error) of a promise
+type deliveryResult struct {
+	value interface{}
+	kind  deliveryKind
+}
 
 // resolved is used in cases where we want to return a successul promise
-var resolved = NewPromise().Succeed()
+var resolved = newPromise().Succeed()
 
 // NewPromise creates an instance of promise which implements Controller
 // (and therefore, implements Promise)
+//
+//	Notes
+//		If a default timeout has been set via SetDefaultTimeout, the promise
+//		fails with ErrPromiseTimeout if it is not delivered within the
+//		default timeout
+//
+//		Delivery (SucceedWithResult, Fail, etc.) does not return until the
+//		handlers have been invoked. The handlers registered before delivery
+//		are invoked synchronously by the delivering goroutine, and a handler
+//		registered after delivery is invoked synchronously by the
+//		registering goroutine. A promise created WithExecutor is not
+//		synchronous, as the handlers registered before delivery are invoked
+//		via the Executor, and delivery may return before they are invoked
+//
 func NewPromise() Controller {
+	p := newPromise()
+
+	p.applyDefaultTimeout()
+
+	return p
+}
+
+// NewPromisePair creates a promise, and returns its producer (Controller)
+// and consumer (Promise) views
+//
+//	Notes
+//		Both views are the same underlying promise, so delivering the
+//		Controller notifies the handlers registered via the Promise. The
+//		split makes it explicit which view is handed out to consumers and
+//		which is kept private by the producer
+//
+func NewPromisePair() (Controller, Promise) {
+	p := newPromise()
+
+	p.applyDefaultTimeout()
+
+	return p, p
+}
+
+// newPromise creates an instance of promise
+//
+//	Notes
+//		The default timeout is not applied, as newPromise is also used for
+//		the promises that are derived from other promises (chains, timeouts,
+//		combinators, etc.), which are delivered by the promises they derive
+//		from. Public constructors apply it via applyDefaultTimeout
+//
+func newPromise() *promise {
 	return &promise{}
 }
 
+// applyDefaultTimeout fails the promise with ErrPromiseTimeout if it is not
+// delivered within the default timeout (if any)
+func (p *promise) applyDefaultTimeout() {
+	if d := DefaultTimeout(); d > 0 {
+		p.expireAfter(d)
+	}
+}
+
+// NewPromiseSlice creates n promises, using a single allocation for all of
+// them
+//
+//	Notes
+//		As with NewPromise, the default timeout (if any) is applied to each
+//		of the promises
+//
+func NewPromiseSlice(n int) []Controller {
+	promises := make([]promise, n)
+	controllers := make([]Controller, n)
+
+	for i := range promises {
+		promises[i].applyDefaultTimeout()
+
+		controllers[i] = &promises[i]
+	}
+
+	return controllers
+}
+
+// NewErrorPromise creates a promise that has failed with an error formatted
+// via fmt.Errorf
+//
+//	Notes
+//		As with fmt.Errorf, the %w verb wraps an error argument
+//
+func NewErrorPromise(format string, args ...interface{}) Controller {
+	return newPromise().Fail(fmt.Errorf(format, args...))
+}
+
+// NewErrorfPromise is an alias for NewErrorPromise
+func NewErrorfPromise(format string, args ...interface{}) Controller {
+	return NewErrorPromise(format, args...)
+}
+
+// NewPromiseFunc creates a promise that is delivered via the resolve and
+// reject callbacks passed to fn
+//
+//	Notes
+//		This is the analog of the JavaScript Promise constructor:
+//		new Promise((resolve, reject) => {...})
+//
+//		fn is invoked synchronously, but may call resolve or reject at any
+//		time (for example, from a goroutine started by fn). Only the first
+//		call to resolve or reject delivers the promise, and any subsequent
+//		calls are ignored
+//
+//		If fn panics, the promise is rejected with an error for the panic
+//
+func NewPromiseFunc(fn func(resolve func(interface{}), reject func(error))) Promise {
+	p := newPromise()
+
+	p.applyDefaultTimeout()
+
+	resolve := func(result interface{}) {
+		p.tryDeliver(result)
+	}
+
+	reject := func(err error) {
+		p.tryDeliver(err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				reject(fmt.Errorf("promise func panic'd: %v", r))
+			}
+		}()
+
+		fn(resolve, reject)
+	}()
+
+	return p
+}
+
 // IsDelivered determines if the promise has been delivered
 func (p *promise) IsDelivered() bool {
 	return p.result.Load() != nil
@@ -57,8 +221,8 @@ func (p *promise) IsPending() bool {
 func (p *promise) Error() (err error) {
 	res := p.result.Load()
 
-	if res != nil {
-		err, _ = res.(error)
+	if res != nil && res.kind != deliveredSuccess {
+		err = res.value.(error)
 	}
 
 	return
@@ -72,11 +236,11 @@ func (p *promise) Error() (err error) {
 //
 func (p *promise) RawResult() interface{} {
 	res := p.result.Load()
-	if res == nilResult {
-		res = nil
+	if res == nil {
+		return nil
 	}
 
-	return res
+	return res.value
 }
 
 // Result returns the successful result of the delivery or nil
@@ -89,20 +253,12 @@ func (p *promise) RawResult() interface{} {
 func (p *promise) Result() interface{} {
 	res := p.result.Load()
 
-	if res != nil {
-		// if the result represents an error return nil
-		if _, ok := res.(error); ok {
-			return nil
-		}
-
-		// if the delivery was == nil, then we use nilResult to indicate
-		// and now we need to translate it back to nil
-		if res == nilResult {
-			return nil
-		}
+	// if not delivered, or the result represents an error return nil
+	if res == nil || res.kind != deliveredSuccess {
+		return nil
 	}
 
-	return res
+	return res.value
 }
 
 // IsFailed determines if the promise has been delivered with an error
@@ -128,22 +284,21 @@ func (p *promise) IsSuccess() bool {
 		return false
 	}
 
-	// success depends on whether res is / isn't an error
-	_, ok := res.(error)
-
-	return !ok
+	return res.kind == deliveredSuccess
 }
 
 // IsCanceled determines if the promise delivery has been canceled
 func (p *promise) IsCanceled() bool {
-	return p.Error() == ErrPromiseCanceled
+	res := p.result.Load()
+
+	return res != nil && res.kind == deliveredCanceled
 }
 
 // notifySuccess invokes a SuccessHandler with panic recovery
 func (p *promise) notifySuccess(handler SuccessHandler, result interface{}) {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("success handler panic'd: %s", r)
+			p.logf("success handler panic'd: %s", r)
 		}
 	}()
 
@@ -154,7 +309,7 @@ func (p *promise) notifySuccess(handler SuccessHandler, result interface{}) {
 func (p *promise) notifyAlways(handler AlwaysHandler) {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("always handler panic'd: %s", r)
+			p.logf("always handler panic'd: %s", r)
 		}
 	}()
 
@@ -165,7 +320,7 @@ func (p *promise) notifyAlways(handler AlwaysHandler) {
 func (p *promise) notifyCatch(handler CatchHandler, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("catch handler panic'd: %s", r)
+			p.logf("catch handler panic'd: %s", r)
 		}
 	}()
 
@@ -176,57 +331,13 @@ func (p *promise) notifyCatch(handler CatchHandler, err error) {
 func (p *promise) notifyCanceled(handler CanceledHandler) {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("canceled handler panic'd: %s", r)
+			p.logf("canceled handler panic'd: %s", r)
 		}
 	}()
 
 	handler()
 }
 
-// copySuccessHandlers creates a copy of the handlers for notification
-func (p *promise) copySuccessHandlers() []SuccessHandler {
-	p.lock.Lock()
-	defer p.lock.Unlock()
-
-	handlers := make([]SuccessHandler, len(p.successHandlers))
-	copy(handlers, p.successHandlers)
-
-	return handlers
-}
-
-// copyCatchHandlers creates a copy of the handlers for notification
-func (p *promise) copyCatchHandlers() []CatchHandler {
-	p.lock.Lock()
-	defer p.lock.Unlock()
-
-	handlers := make([]CatchHandler, len(p.catchHandlers))
-	copy(handlers, p.catchHandlers)
-
-	return handlers
-}
-
-// copyAlwaysHandlers creates a copy of the handlers for notification
-func (p *promise) copyAlwaysHandlers() []AlwaysHandler {
-	p.lock.Lock()
-	defer p.lock.Unlock()
-
-	handlers := make([]AlwaysHandler, len(p.alwaysHandlers))
-	copy(handlers, p.alwaysHandlers)
-
-	return handlers
-}
-
-// copyCanceledHandlers creates a copy of the handlers for notification
-func (p *promise) copyCanceledHandlers() []CanceledHandler {
-	p.lock.Lock()
-	defer p.lock.Unlock()
-
-	handlers := make([]CanceledHandler, len(p.canceledHandlers))
-	copy(handlers, p.canceledHandlers)
-
-	return handlers
-}
-
 // notify invokes the appropriate callbacks based on the delivered result
 // of the promise
 //
@@ -236,46 +347,65 @@ func (p *promise) copyCanceledHandlers() []CanceledHandler {
 //
 //		As the name suggests, always handlers are always invoked
 //
-//		Because we cannot take the lock during the notification, we must
-//		make a copy of the appropriate handler arrays so they are not modified
-//		while we are notifying
+//		All of the handler lists are closed before any handler is invoked,
+//		so a handler registered during notification is invoked directly by
+//		the registration, and the lists can be traversed without copying
 //
 func (p *promise) notify() {
+	order := HandlerOrder(p.order.Load())
+
+	successHandlers := p.successHandlers.close(order)
+	catchHandlers := p.catchHandlers.close(order)
+	canceledHandlers := p.canceledHandlers.close(order)
+	alwaysHandlers := p.alwaysHandlers.close(order)
+
 	if p.IsSuccess() {
 		res := p.Result()
 
-		handlers := p.copySuccessHandlers()
-		for _, handler := range handlers {
-			p.notifySuccess(handler, res)
+		for node := successHandlers; node != nil; node = node.next {
+			p.notifySuccess(node.handler.(SuccessHandler), res)
 		}
 	} else {
 		err := p.Error()
 
 		// invoke the catch handlers, even if err == ErrPromiseCanceled
-		handlers := p.copyCatchHandlers()
-		for _, handler := range handlers {
-			p.notifyCatch(handler, err)
+		for node := catchHandlers; node != nil; node = node.next {
+			p.notifyCatch(node.handler.(CatchHandler), err)
 		}
 
 		// if canceled, invoke cancel handlers
 		if err == ErrPromiseCanceled {
-			handlers := p.copyCanceledHandlers()
-			for _, handler := range handlers {
-				p.notifyCanceled(handler)
+			for node := canceledHandlers; node != nil; node = node.next {
+				p.notifyCanceled(node.handler.(CanceledHandler))
 			}
 		}
 	}
 
-	handlers := p.copyAlwaysHandlers()
-	for _, handler := range handlers {
-		p.notifyAlways(handler)
+	for node := alwaysHandlers; node != nil; node = node.next {
+		p.notifyAlways(node.handler.(AlwaysHandler))
 	}
 }
 
 // deliver implements the core logic for Promise delivery
 func (p *promise) deliver(result interface{}) Controller {
-	var wasDelivered bool
+	if !p.tryDeliver(result) {
+		// This would be great as a panic, but in 'all' and 'any' scenarios it
+		// is difficult to prevent async code from double completing
+		p.logf("Attempt to deliver promise that is already delivered")
+	}
+
+	return p
+}
 
+// tryDeliver delivers the promise if it is still pending
+//
+//	Notes
+//		Returns true if the promise was delivered by this call, or false if
+//		the promise was already delivered. Use tryDeliver for internal
+//		deliveries that may legitimately race with other deliveries (such
+//		as timers) so that the double delivery is not logged
+//
+func (p *promise) tryDeliver(result interface{}) (wasDelivered bool) {
 	p.lock.Lock()
 	defer func() {
 		// release the lock prior to notifying
@@ -283,7 +413,11 @@ func (p *promise) deliver(result interface{}) Controller {
 
 		// do we need to notify
 		if wasDelivered {
-			p.notify()
+			if p.ext != nil && p.ext.executor != nil {
+				p.ext.executor.Execute(p.notify)
+			} else {
+				p.notify()
+			}
 		}
 	}()
 
@@ -291,20 +425,20 @@ func (p *promise) deliver(result interface{}) Controller {
 		// invoke callbacks via notify()
 		wasDelivered = true
 
-		// if nil is delivered, use nilResult as a non-nil place holder
-		if result == nil {
-			result = nilResult
+		// determine the kind of delivery from the result
+		p.delivery.value = result
+		if err, ok := result.(error); ok {
+			p.delivery.kind = deliveredError
+			if err == ErrPromiseCanceled {
+				p.delivery.kind = deliveredCanceled
+			}
 		}
 
 		// store the delivered result
-		p.result.Store(result)
-	} else {
-		// This would be great as a panic, but in 'all' and 'any' scenarios it
-		// is difficult to prevent async code from double completing
-		log.Println("Attempt to deliver promise that is already delivered")
+		p.result.Store(&p.delivery)
 	}
 
-	return p
+	return
 }
 
 // Allows a wait on promise delivery via a channel
@@ -389,36 +523,22 @@ func (p *promise) Deliver(result interface{}) Controller {
 // Success registers a callback on successful delivery of the promise
 //
 //	Notes
-//		This method locks so that concurrent access cannot change
-//		from !delivered to delivered while we are in this routine,
-//    as that would lead to non-deterministic invokcation of the
-//		callback
+//		This method does not lock. The handler is atomically added to a
+//		lock-free list that is closed when the promise is delivered, so if
+//		the delivery races with this routine, the handler is either invoked
+//		by the delivery or invoked directly by this routine
 //
 //		If the promise is already delivered when this nethod is called
 //		then invocation of the callback is synchronous, otherwise it
 //		is non-synchronous
 //
 func (p *promise) Success(handler SuccessHandler) Promise {
-	var notify bool
-
-	p.lock.Lock()
-	defer func() {
-		// release the lock (before calling handler)
-		p.lock.Unlock()
+	p.countHandler("Success")
 
-		// do we need to directly notify?
-		if notify {
-			handler(p.Result())
-		}
-	}()
-
-	// already delivered and successful?
-	if p.IsSuccess() {
+	// if the list is closed, the promise is delivered
+	if !p.successHandlers.add(handler) && p.IsSuccess() {
 		// direct invoke
-		notify = true
-	} else {
-		// deferred invoke
-		p.successHandlers = append(p.successHandlers, handler)
+		handler(p.Result())
 	}
 
 	return p
@@ -427,36 +547,22 @@ func (p *promise) Success(handler SuccessHandler) Promise {
 // Catch registers a callback on a failed delivery of the promise
 //
 //	Notes
-//		This method locks so that concurrent access cannot change
-//		from !delivered to delivered while we are in this routine,
-//    as that would lead to non-deterministic invokcation of the
-//		callback
+//		This method does not lock. The handler is atomically added to a
+//		lock-free list that is closed when the promise is delivered, so if
+//		the delivery races with this routine, the handler is either invoked
+//		by the delivery or invoked directly by this routine
 //
 //		If the promise is already delivered when this nethod is called
 //		then invocation of the callback is synchronous, otherwise it
 //		is non-synchronous
 //
 func (p *promise) Catch(handler CatchHandler) Promise {
-	var notify bool
+	p.countHandler("Catch")
 
-	p.lock.Lock()
-	defer func() {
-		// release the lock (before invoking handler)
-		p.lock.Unlock()
-
-		// is direct notify?
-		if notify {
-			handler(p.Error())
-		}
-	}()
-
-	// is delivered and error?
-	if p.IsError() {
+	// if the list is closed, the promise is delivered
+	if !p.catchHandlers.add(handler) && p.IsError() {
 		// direct invoke
-		notify = true
-	} else {
-		// deferred invoke
-		p.catchHandlers = append(p.catchHandlers, handler)
+		handler(p.Error())
 	}
 
 	return p
@@ -466,36 +572,22 @@ func (p *promise) Catch(handler CatchHandler) Promise {
 // is canceled
 //
 //	Notes
-//		This method locks so that concurrent access cannot change
-//		from !delivered to delivered while we are in this routine,
-//    as that would lead to non-deterministic invokcation of the
-//		callback
+//		This method does not lock. The handler is atomically added to a
+//		lock-free list that is closed when the promise is delivered, so if
+//		the delivery races with this routine, the handler is either invoked
+//		by the delivery or invoked directly by this routine
 //
 //		If the promise is already delivered when this nethod is called
 //		then invocation of the callback is synchronous, otherwise it
 //		is non-synchronous
 //
 func (p *promise) Canceled(handler CanceledHandler) Promise {
-	var notify bool
+	p.countHandler("Canceled")
 
-	p.lock.Lock()
-	defer func() {
-		// release the lock (before invoking handler)
-		p.lock.Unlock()
-
-		// is direct notify?
-		if notify {
-			handler()
-		}
-	}()
-
-	// is delivered and canceled?
-	if p.IsCanceled() {
+	// if the list is closed, the promise is delivered
+	if !p.canceledHandlers.add(handler) && p.IsCanceled() {
 		// direct invoke
-		notify = true
-	} else {
-		// deferred invoke
-		p.canceledHandlers = append(p.canceledHandlers, handler)
+		handler()
 	}
 
 	return p
@@ -504,41 +596,85 @@ func (p *promise) Canceled(handler CanceledHandler) Promise {
 // Always registers a callback when the promise is delivered or canceled
 //
 //	Notes
-//		This method locks so that concurrent access cannot change
-//		from !delivered to delivered while we are in this routine,
-//    as that would lead to non-deterministic invokcation of the
-//		callback
+//		This method does not lock. The handler is atomically added to a
+//		lock-free list that is closed when the promise is delivered, so if
+//		the delivery races with this routine, the handler is either invoked
+//		by the delivery or invoked directly by this routine
 //
 //		If the promise is already delivered when this nethod is called
 //		then invocation of the callback is synchronous, otherwise it
 //		is non-synchronous
 //
 func (p *promise) Always(handler AlwaysHandler) Promise {
-	var notify bool
+	p.countHandler("Always")
 
-	p.lock.Lock()
-	defer func() {
-		// release the lock
-		p.lock.Unlock()
+	// if the list is closed, the promise is delivered
+	if !p.alwaysHandlers.add(handler) && p.IsDelivered() {
+		// direct invoke
+		handler(p)
+	}
 
-		// is direct notify?
-		if notify {
-			handler(p)
-		}
-	}()
+	return p
+}
+
+// SuccessPriority registers a callback, with a priority, on successful
+// delivery of the promise
+func (p *promise) SuccessPriority(priority int, handler SuccessHandler) Promise {
+	p.countHandler("SuccessPriority")
 
-	// if its delivered then direct notify
-	if p.IsDelivered() {
+	// if the list is closed, the promise is delivered
+	if !p.successHandlers.addPriority(priority, handler) && p.IsSuccess() {
 		// direct invoke
-		notify = true
-	} else {
-		// deferred invoke
-		p.alwaysHandlers = append(p.alwaysHandlers, handler)
+		handler(p.Result())
 	}
 
 	return p
 }
 
+// handlerContext returns the context passed to context handlers, which is
+// the creation context of the promise (if any), otherwise ctx
+func (p *promise) handlerContext(ctx context.Context) context.Context {
+	if p.ext != nil && p.ext.ctx != nil {
+		return p.ext.ctx
+	}
+
+	if ctx == nil {
+		return context.Background()
+	}
+
+	return ctx
+}
+
+// SuccessCtx registers a callback on successful delivery of the promise
+// that receives ctx
+func (p *promise) SuccessCtx(ctx context.Context, handler SuccessHandlerWithContext) Promise {
+	ctx = p.handlerContext(ctx)
+
+	return p.Success(func(result interface{}) {
+		handler(ctx, result)
+	})
+}
+
+// CatchCtx registers a callback on a failed delivery of the promise that
+// receives ctx
+func (p *promise) CatchCtx(ctx context.Context, handler CatchHandlerWithContext) Promise {
+	ctx = p.handlerContext(ctx)
+
+	return p.Catch(func(err error) {
+		handler(ctx, err)
+	})
+}
+
+// AlwaysCtx registers a callback when the promise is delivered or canceled
+// that receives ctx
+func (p *promise) AlwaysCtx(ctx context.Context, handler AlwaysHandlerWithContext) Promise {
+	ctx = p.handlerContext(ctx)
+
+	return p.Always(func(p2 Controller) {
+		handler(ctx, p2)
+	})
+}
+
 // Chain a Promise to the successful delivery of this Promise
 //
 //	Notes
@@ -557,7 +693,7 @@ func (p *promise) Then(promise Promise) Promise {
 //		the result of the Then promise
 //
 func (p *promise) Thenf(factory Factory) Promise {
-	result := NewPromise()
+	result := newPromise()
 
 	p.Always(func(p2 Controller) {
 		if p2.IsSuccess() {
@@ -580,7 +716,7 @@ func (p *promise) Thenf(factory Factory) Promise {
 //		the result of the Then promise
 //
 func (p *promise) ThenWithResult(factory FactoryWithResult) Promise {
-	result := NewPromise()
+	result := newPromise()
 
 	p.Always(func(p2 Controller) {
 		if p2.IsSuccess() {
@@ -598,7 +734,7 @@ func (p *promise) ThenWithResult(factory FactoryWithResult) Promise {
 // ThenAllWithResult chains the result of a successful promise to a collection
 // of promises that use the original result
 func (p *promise) ThenAllWithResult(factory ...FactoryWithResult) Promise {
-	result := NewPromise()
+	result := newPromise()
 
 	p.Always(func(p2 Controller) {
 		if p2.IsSuccess() {
@@ -612,7 +748,7 @@ func (p *promise) ThenAllWithResult(factory ...FactoryWithResult) Promise {
 			}
 
 			// wait for all the promises to be delivered
-			result.DeliverWithPromise(p.all(promises).(Controller))
+			result.DeliverWithPromise(all(promises).(Controller))
 		} else {
 			result.DeliverWithPromise(p2)
 		}
@@ -621,8 +757,21 @@ func (p *promise) ThenAllWithResult(factory ...FactoryWithResult) Promise {
 	return result
 }
 
-// all is a base implementtion of ThenAll
-func (p *promise) all(promises []Promise) Promise {
+// outcome returns a promise that is delivered with the Controller passed
+// to the Always handler of p, so the result of p can be read even when p
+// is not a Controller
+func outcome(p Promise) *promise {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		result.DeliverWithPromise(p2)
+	})
+
+	return result
+}
+
+// all is a base implementtion of ThenAll and All
+func all(promises []Promise) Promise {
 	// how many promises must complete?
 	count := int64(len(promises))
 
@@ -632,7 +781,7 @@ func (p *promise) all(promises []Promise) Promise {
 	}
 
 	// create a promise to bridge this promise and the 'all' promises
-	result := NewPromise()
+	result := newPromise()
 
 	for _, promise := range promises {
 		// attach an always handler and based on the result do the right thing
@@ -659,49 +808,552 @@ func (p *promise) all(promises []Promise) Promise {
 
 // Chain a list of Promises to the successful delivery of this Promise
 func (p *promise) ThenAll(promises ...Promise) Promise {
-	return p.Then(p.all(promises))
+	return p.Then(all(promises))
 }
 
 // Chain a list of Promises (created via Factory) to the successful
 // delivery of this Promise
 func (p *promise) ThenAllf(factory func() []Promise) Promise {
-	return p.Then(p.all(factory()))
+	return p.Then(all(factory()))
 }
 
-// any is a base implementation of ThenAny
-func (p *promise) any(promises []Promise) Promise {
-	// if there are no any promises, then success
-	if len(promises) == 0 {
-		return resolved
+// Chain a list of Promises to the successful delivery of this Promise
+func (p *promise) ThenAny(promises ...Promise) Promise {
+	return p.Then(firstSuccess(promises, false))
+}
+
+// Chain a list of Promises (created via Factory) to the successful
+// delivery of this Promise
+func (p *promise) ThenAnyf(factory func() []Promise) Promise {
+	return p.Then(firstSuccess(factory(), false))
+}
+
+// Map2 combines the results of this promise and another promise using
+// a transform
+func (p *promise) Map2(other Promise, fn func(a, b interface{}) (interface{}, error)) Promise {
+	result := newPromise()
+	b := outcome(other)
+
+	all([]Promise{p, b}).Always(func(p2 Controller) {
+		if p2.IsSuccess() {
+			value, err := fn(p.Result(), b.Result())
+			if err != nil {
+				result.Fail(err)
+			} else {
+				result.SucceedWithResult(value)
+			}
+		} else {
+			result.DeliverWithPromise(p2)
+		}
+	})
+
+	return result
+}
+
+// ThenAllWithResultsAndErrors chains a list of Promises to the successful
+// delivery of this Promise, and waits for all of them to be delivered
+func (p *promise) ThenAllWithResultsAndErrors(promises ...Promise) Promise {
+	return p.Thenf(func() Promise {
+		return settledControllers(promises).ThenWithResult(func(result interface{}) Promise {
+			controllers := result.([]Controller)
+			all := AllResults{
+				Values: make([]interface{}, len(controllers)),
+				Errors: make([]error, len(controllers)),
+			}
+
+			for i, p2 := range controllers {
+				all.Values[i] = p2.Result()
+				all.Errors[i] = p2.Error()
+			}
+
+			return newPromise().SucceedWithResult(all)
+		})
+	})
+}
+
+// ThenAllSettled chains a list of Promises to the successful delivery of
+// this Promise, and waits for all of them to be delivered
+func (p *promise) ThenAllSettled(promises ...Promise) Promise {
+	return p.Thenf(func() Promise {
+		return settledResults(promises)
+	})
+}
+
+// ThenN chains a list of Promises to the successful delivery of this
+// Promise, and succeeds once n of them have succeeded
+func (p *promise) ThenN(n int, promises ...Promise) Promise {
+	return p.Thenf(func() Promise {
+		return WhenN(n, promises...)
+	})
+}
+
+// CatchChain chains a Promise (created via fn) to the failed delivery of
+// this Promise, allowing recovery from an error
+func (p *promise) CatchChain(fn func(err error) Promise) Promise {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		if p2.IsFailed() {
+			fn(p2.Error()).Always(func(p3 Controller) {
+				result.DeliverWithPromise(p3)
+			})
+		} else {
+			result.DeliverWithPromise(p2)
+		}
+	})
+
+	return result
+}
+
+// ThenAllLazy chains a list of Promises (created on demand via Factory)
+// to the successful delivery of this Promise
+func (p *promise) ThenAllLazy(maxConcurrent int, factories ...Factory) Promise {
+	return p.Thenf(func() Promise {
+		return lazyAll(maxConcurrent, factories)
+	})
+}
+
+// ThenWithResultAll chains the result of a successful promise to a list
+// of Promises created from the result
+func (p *promise) ThenWithResultAll(factory func(result interface{}) []Promise) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		return all(factory(result))
+	})
+}
+
+// ThenWithResultAny chains the result of a successful promise to a list
+// of Promises created from the result
+func (p *promise) ThenWithResultAny(factory func(result interface{}) []Promise) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		return firstSuccess(factory(result), false)
+	})
+}
+
+// OrderedThenAll chains a list of Promises to the successful delivery of
+// this Promise, where the promises are considered in order
+func (p *promise) OrderedThenAll(promises ...Promise) Promise {
+	return p.Then(ordered(promises))
+}
+
+// ThenWithRecovery chains a Promise to the delivery of this Promise,
+// using factory on success or recovery on failure
+func (p *promise) ThenWithRecovery(factory FactoryWithResult, recovery func(err error) Promise) Promise {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		var next Promise
+
+		if p2.IsSuccess() {
+			next = factory(p2.Result())
+		} else {
+			next = recovery(p2.Error())
+		}
+
+		next.Always(func(p3 Controller) {
+			result.DeliverWithPromise(p3)
+		})
+	})
+
+	return result
+}
+
+// ThenAnyWithResult chains the first success from a list of Promises to
+// the successful delivery of this Promise
+func (p *promise) ThenAnyWithResult(promises ...Promise) Promise {
+	return p.Thenf(func() Promise {
+		return firstSuccess(promises, false)
+	})
+}
+
+// ThenAnyWithResultf chains the first success from a list of Promises
+// (created via factory) to the successful delivery of this Promise
+func (p *promise) ThenAnyWithResultf(factory func() []Promise) Promise {
+	return p.Thenf(func() Promise {
+		return firstSuccess(factory(), false)
+	})
+}
+
+// ToFuture returns a Future backed by this Promise
+func (p *promise) ToFuture() Future {
+	return &future{promise: p}
+}
+
+// ThenAllWithResults chains a list of Promises to the successful
+// delivery of this Promise, and collects their results
+func (p *promise) ThenAllWithResults(promises ...Promise) Promise {
+	return p.Thenf(func() Promise {
+		return allWithResults(promises, false)
+	})
+}
+
+// ThenAllWithResultsCanceled chains a list of Promises to the successful
+// delivery of this Promise, and collects their results
+func (p *promise) ThenAllWithResultsCanceled(promises ...Promise) Promise {
+	return p.Thenf(func() Promise {
+		return allWithResults(promises, true)
+	})
+}
+
+// ThenPair chains a Promise (created via factory from the results of
+// this promise and another promise) to the successful delivery of both
+func (p *promise) ThenPair(other Promise, factory func(a, b interface{}) Promise) Promise {
+	b := outcome(other)
+
+	return all([]Promise{p, b}).Thenf(func() Promise {
+		return factory(p.Result(), b.Result())
+	})
+}
+
+// WithHandlerOrder sets the order in which the handlers of this promise are
+// invoked
+func (p *promise) WithHandlerOrder(order HandlerOrder) Controller {
+	p.order.Store(int32(order))
+
+	return p
+}
+
+// ThenAllWithResultsMap chains a list of Promises to the successful
+// delivery of this Promise, and collects their results by key
+func (p *promise) ThenAllWithResultsMap(keyFn func(i int, result interface{}) string, promises ...Promise) Promise {
+	return p.Thenf(func() Promise {
+		return allWithResults(promises, false)
+	}).ThenWithResult(func(result interface{}) Promise {
+		results := result.([]interface{})
+		m := make(map[string]interface{}, len(results))
+
+		for i, r := range results {
+			m[keyFn(i, r)] = r
+		}
+
+		return newPromise().SucceedWithResult(m)
+	})
+}
+
+// ThenChain chains a sequence of Promises (created via factories) to the
+// successful delivery of this Promise
+func (p *promise) ThenChain(factories ...FactoryWithResult) Promise {
+	var result Promise = p
+
+	for _, factory := range factories {
+		result = result.ThenWithResult(factory)
 	}
 
-	// create a bridge promise between this promise and the any promises
-	result := NewPromise()
+	return result
+}
 
-	for _, promise := range promises {
-		// add an always handler for each promise
-		promise.Always(func(p2 Controller) {
-			// deliver result based on result of promise. For Any, we only need
-			// one promise to deliver, not all of them (see all([]Promise))
+// ThenWithResultMap chains the result of a successful promise to a named
+// set of Promises created from the result
+func (p *promise) ThenWithResultMap(factory func(result interface{}) map[string]Promise) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		return allWithResultsMap(factory(result))
+	})
+}
+
+// ThenWithTimeout chains the result of a successful promise to another
+// promise, which must be delivered within d
+func (p *promise) ThenWithTimeout(d time.Duration, factory FactoryWithResult) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		return withTimeout(factory(result), d)
+	})
+}
+
+// ThenfWithTimeout chains a successful promise to another promise, which
+// must be delivered within d
+func (p *promise) ThenfWithTimeout(d time.Duration, factory Factory) Promise {
+	return p.Thenf(func() Promise {
+		return withTimeout(factory(), d)
+	})
+}
+
+// IgnoreErrors returns a promise that succeeds with a nil result if this
+// promise fails with one of the target errors
+func (p *promise) IgnoreErrors(targets ...error) Promise {
+	return p.ignore(func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// IgnoreErrorTypes returns a promise that succeeds with a nil result if
+// this promise fails with an error of one of the types
+func (p *promise) IgnoreErrorTypes(types ...interface{}) Promise {
+	// resolve the types up front, so that an invalid type panics here
+	// rather than in a handler
+	targets := make([]reflect.Type, len(types))
+	for i, t := range types {
+		targets[i] = errorType(t)
+	}
+
+	return p.ignore(func(err error) bool {
+		for _, target := range targets {
+			// use a new target for each match, as errors.As writes to it
+			if errors.As(err, reflect.New(target).Interface()) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// errorType returns the error type specified by t, which is either a
+// pointer to an error type (or interface), as for the target of errors.As,
+// or a value of an error type
+//
+//	Notes
+//		errorType panics if t does not specify an error type
+//
+func errorType(t interface{}) reflect.Type {
+	rt := reflect.TypeOf(t)
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+
+	switch {
+	case rt == nil:
+		// nil does not specify a type
+	case rt.Kind() == reflect.Ptr && (rt.Elem().Kind() == reflect.Interface || rt.Elem().Implements(errType)):
+		return rt.Elem()
+	case rt.Implements(errType):
+		return rt
+	}
+
+	panic(fmt.Errorf("IgnoreErrorTypes: %T does not specify an error type", t))
+}
+
+// ignore returns a promise that succeeds with a nil result if this promise
+// fails with an error accepted by match
+func (p *promise) ignore(match func(err error) bool) Promise {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		if p2.IsFailed() && match(p2.Error()) {
+			result.SucceedWithResult(nil)
+		} else {
 			result.DeliverWithPromise(p2)
+		}
+	})
+
+	return result
+}
+
+// ThenSwitchAll chains the result of a successful promise to a Promise
+// created by the factory selected for the result
+func (p *promise) ThenSwitchAll(selector func(result interface{}) string, cases map[string]FactoryWithResult, defaultCase FactoryWithResult, merge func(result interface{}) []Promise) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		factory, ok := cases[selector(result)]
+		if !ok {
+			factory = defaultCase
+		}
+
+		if factory == nil {
+			return newPromise().Fail(ErrNoMatchingCase)
+		}
+
+		if merge == nil {
+			return factory(result)
+		}
+
+		return factory(result).ThenWithResult(func(result interface{}) Promise {
+			return allWithResults(merge(result), false)
 		})
+	})
+}
 
-		// early-out in case the promise got delivered synchronously
-		if result.IsDelivered() {
-			break
+// MapError returns a promise that transforms the error of a failed
+// delivery of this promise via fn
+func (p *promise) MapError(fn func(err error) error) Promise {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		if p2.IsFailed() && !p2.IsCanceled() {
+			result.Fail(mapError(p2.Error(), fn))
+		} else {
+			result.DeliverWithPromise(p2)
+		}
+	})
+
+	return result
+}
+
+// ThenWithResultCatch chains a Promise to the delivery of this Promise,
+// using factory on success or catch on failure
+func (p *promise) ThenWithResultCatch(factory FactoryWithResult, catch func(err error) Promise) Promise {
+	return p.ThenWithRecovery(factory, catch)
+}
+
+// ConvertError returns a promise that fails with to if this promise fails
+// with from, and otherwise mirrors this promise
+func (p *promise) ConvertError(from, to error) Promise {
+	return p.ConvertErrors(map[error]error{from: to})
+}
+
+// ConvertErrors returns a promise that fails with mapping[from] if this
+// promise fails with an error matching from, and otherwise mirrors this
+// promise
+func (p *promise) ConvertErrors(mapping map[error]error) Promise {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		if p2.IsFailed() {
+			for from, to := range mapping {
+				if errors.Is(p2.Error(), from) {
+					result.Fail(to)
+					return
+				}
+			}
+		}
+
+		result.DeliverWithPromise(p2)
+	})
+
+	return result
+}
+
+// ThenWithResultRetry chains the result of a successful promise to
+// another promise, retrying up to n times if it fails
+func (p *promise) ThenWithResultRetry(n int, factory FactoryWithResult) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		return NewRetryWithPolicy(ConstantBackoff(0, n), func() Promise {
+			return factory(result)
+		})
+	})
+}
+
+// ThenWithResultWindow chains windows of the result of a successful
+// promise to Promises (created via factory) that run concurrently
+func (p *promise) ThenWithResultWindow(windowSize int, factory FactoryWithResult) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		results, ok := result.([]interface{})
+		if !ok {
+			return newPromise().Fail(ErrResultNotSlice)
 		}
+
+		size := windowSize
+		if size <= 0 {
+			size = len(results)
+		}
+
+		var promises []Promise
+		for start := 0; start < len(results); start += size {
+			end := start + size
+			if end > len(results) {
+				end = len(results)
+			}
+
+			promises = append(promises, factory(results[start:end:end]))
+		}
+
+		return allWithResults(promises, false)
+	})
+}
+
+// ThenRetryWithBackoff chains the result of a successful promise to
+// another promise, retrying with exponential backoff if it fails
+func (p *promise) ThenRetryWithBackoff(maxAttempts int, initial time.Duration, multiplier float64, factory FactoryWithResult) Promise {
+	policy := &limitedBackoff{
+		BackoffPolicy: ExponentialBackoff(initial, math.MaxInt64, multiplier, false),
+		maxAttempts:   maxAttempts,
+	}
+
+	result := newPromise()
+
+	// the retries stop when the creation context of this promise is done
+	if p.ext != nil && p.ext.ctx != nil {
+		result.cancelOnContext(p.ext.ctx)
 	}
 
+	p.Always(func(p2 Controller) {
+		if !p2.IsSuccess() {
+			result.tryDeliver(p2.RawResult())
+			return
+		}
+
+		if result.IsDelivered() {
+			return
+		}
+
+		retry := NewRetryWithPolicy(policy, func() Promise {
+			return factory(p2.Result())
+		})
+
+		// canceling the result stops the retries
+		result.Canceled(func() {
+			cancelPending(retry)
+		})
+
+		retry.Always(func(p3 Controller) {
+			result.tryDeliver(p3.RawResult())
+		})
+	})
+
 	return result
 }
 
-// Chain a list of Promises to the successful delivery of this Promise
-func (p *promise) ThenAny(promises ...Promise) Promise {
-	return p.Then(p.any(promises))
+// ThenWithResultOrTimeout chains the result of a successful promise to
+// another promise, which is raced against a timeout promise
+func (p *promise) ThenWithResultOrTimeout(d time.Duration, factory FactoryWithResult, timeout Promise) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		if timeout == nil {
+			return withTimeout(factory(result), d)
+		}
+
+		raced := newPromise()
+
+		factory(result).Always(func(p2 Controller) {
+			raced.tryDeliver(p2.RawResult())
+		})
+
+		timeout.Always(func(Controller) {
+			raced.tryDeliver(ErrPromiseTimeout)
+		})
+
+		return raced
+	})
 }
 
-// Chain a list of Promises (created via Factory) to the successful
-// delivery of this Promise
-func (p *promise) ThenAnyf(factory func() []Promise) Promise {
-	return p.Then(p.any(factory()))
+// maxStringResult is the maximum length of the result included by String
+const maxStringResult = 64
+
+// String returns the state of the promise for debugging
+func (p *promise) String() string {
+	switch {
+	case p.IsPending():
+		return "Promise[Pending]"
+	case p.IsCanceled():
+		return "Promise[Canceled]"
+	case p.IsFailed():
+		return fmt.Sprintf("Promise[Failed: %s]", p.Error())
+	}
+
+	result := []rune(fmt.Sprintf("%v", p.Result()))
+	if len(result) > maxStringResult {
+		result = result[:maxStringResult]
+	}
+
+	return fmt.Sprintf("Promise[Succeeded: %s]", string(result))
+}
+
+// ThenWithResultConcurrent chains each element of the result of a
+// successful promise to a Promise (created via factory), with at most
+// concurrency promises in-flight
+func (p *promise) ThenWithResultConcurrent(concurrency int, factory FactoryWithResult) Promise {
+	return p.ThenWithResult(func(result interface{}) Promise {
+		elements, ok := result.([]interface{})
+		if !ok {
+			return newPromise().Fail(ErrResultNotSlice)
+		}
+
+		factories := make([]Factory, len(elements))
+		for i, element := range elements {
+			factories[i] = func() Promise {
+				return factory(element)
+			}
+		}
+
+		return lazyAll(concurrency, factories)
+	})
 }
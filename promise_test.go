@@ -1,7 +1,10 @@
 package promise
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -614,6 +617,38 @@ func TestThenAnyEmpty(t *testing.T) {
 	assert.Equal(t, 1, onSuccess)
 }
 
+func TestThenAnyFirstSuccess(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	p := NewPromise().Succeed().ThenAny(p1, p2)
+
+	p1.Fail(fmt.Errorf("failed"))
+	assert.True(t, p.(Controller).IsPending())
+
+	p2.SucceedWithResult(42)
+	assert.Equal(t, 42, p.(Controller).Result())
+}
+
+func TestAny(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	p := Any(p1, p2)
+
+	p1.Fail(fmt.Errorf("failed"))
+	assert.True(t, p.(Controller).IsPending())
+
+	p2.SucceedWithResult(42)
+	assert.Equal(t, 42, p.(Controller).Result())
+
+	err := fmt.Errorf("last")
+	p = Any(NewPromise().Fail(fmt.Errorf("first")), NewPromise().Fail(err))
+	assert.Equal(t, err, p.(Controller).Error())
+
+	assert.True(t, Any().(Controller).IsSuccess())
+}
+
 func TestPostSignalNotify(t *testing.T) {
 	p := NewPromise()
 
@@ -720,3 +755,2229 @@ func TestBadAlwaysHandler(t *testing.T) {
 
 	assert.Equal(t, 1, onAlways)
 }
+
+func TestCoalesce(t *testing.T) {
+	p1 := NewPromise().SucceedWithResult(nil)
+	p2 := NewPromise().Fail(fmt.Errorf("test"))
+	p3 := NewPromise().SucceedWithResult(12)
+	p4 := NewPromise().SucceedWithResult(13)
+
+	var onSuccess int
+
+	Coalesce(p1, p2, p3, p4).Success(func(result interface{}) {
+		onSuccess++
+		assert.Equal(t, 12, result)
+	})
+
+	assert.Equal(t, 1, onSuccess)
+}
+
+func TestCoalesceNoResult(t *testing.T) {
+	testErr := fmt.Errorf("Testing coalesce")
+
+	var onCatch int
+
+	Coalesce(NewPromise().Fail(testErr), NewPromise().SucceedWithResult(nil)).Catch(func(err error) {
+		onCatch++
+		assert.Equal(t, testErr, err)
+	})
+
+	Coalesce().Catch(func(err error) {
+		onCatch++
+		assert.Equal(t, ErrNoResult, err)
+	})
+
+	assert.Equal(t, 2, onCatch)
+}
+
+func TestExclusive(t *testing.T) {
+	r := NewExclusiveRegistry()
+
+	var onFactory int
+
+	factory := func() Promise {
+		onFactory++
+		return NewPromise()
+	}
+
+	p1 := r.Exclusive("key", factory)
+	p2 := r.Exclusive("key", factory)
+
+	assert.Equal(t, p1, p2)
+	assert.Equal(t, 1, onFactory)
+
+	// once delivered, the next call starts fresh
+	p1.(Controller).Succeed()
+
+	p3 := r.Exclusive("key", factory)
+	assert.NotEqual(t, p1, p3)
+	assert.Equal(t, 2, onFactory)
+}
+
+func TestNewPromiseWithDeadline(t *testing.T) {
+	p := NewPromiseWithDeadline(time.Now().Add(50 * time.Millisecond))
+
+	assert.True(t, p.IsPending())
+
+	p.Wait(make(chan Controller, 1))
+
+	assert.Equal(t, ErrPromiseTimeout, p.Error())
+}
+
+func TestNewPromiseWithPastDeadline(t *testing.T) {
+	p := NewPromiseWithDeadline(time.Now().Add(-time.Second))
+
+	assert.Equal(t, ErrPromiseTimeout, p.Error())
+}
+
+func TestNewPromiseWithDeadlineDelivered(t *testing.T) {
+	p := NewPromiseWithDeadline(time.Now().Add(50 * time.Millisecond))
+
+	p.SucceedWithResult(12)
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.True(t, p.IsSuccess())
+	assert.Equal(t, 12, p.Result())
+}
+
+func TestMap2(t *testing.T) {
+	p1 := NewPromise().SucceedWithResult(12)
+	p2 := NewPromise().SucceedWithResult(30)
+
+	var onSuccess int
+
+	p1.Map2(p2, func(a, b interface{}) (interface{}, error) {
+		return a.(int) + b.(int), nil
+	}).Success(func(result interface{}) {
+		onSuccess++
+		assert.Equal(t, 42, result)
+	})
+
+	assert.Equal(t, 1, onSuccess)
+
+	// other is not required to be a Controller
+	p := p1.Map2(promiseOnly{p2}, func(a, b interface{}) (interface{}, error) {
+		return a.(int) + b.(int), nil
+	})
+
+	assert.Equal(t, 42, p.(Controller).Result())
+}
+
+// promiseOnly hides the Controller methods of a promise
+type promiseOnly struct {
+	Promise
+}
+
+func TestMap2Fail(t *testing.T) {
+	testErr := fmt.Errorf("Testing Map2")
+
+	var onCatch int
+
+	NewPromise().Succeed().Map2(NewPromise().Fail(testErr), func(a, b interface{}) (interface{}, error) {
+		return nil, nil
+	}).Catch(func(err error) {
+		onCatch++
+		assert.Equal(t, testErr, err)
+	})
+
+	NewPromise().Succeed().Map2(NewPromise().Succeed(), func(a, b interface{}) (interface{}, error) {
+		return nil, testErr
+	}).Catch(func(err error) {
+		onCatch++
+		assert.Equal(t, testErr, err)
+	})
+
+	assert.Equal(t, 2, onCatch)
+}
+
+func TestSetDefaultTimeout(t *testing.T) {
+	SetDefaultTimeout(50 * time.Millisecond)
+	defer SetDefaultTimeout(0)
+
+	assert.Equal(t, 50*time.Millisecond, DefaultTimeout())
+
+	p := NewPromise()
+
+	p.Wait(make(chan Controller, 1))
+
+	assert.Equal(t, ErrPromiseTimeout, p.Error())
+
+	SetDefaultTimeout(0)
+
+	p = NewPromise()
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.True(t, p.IsPending())
+}
+
+func TestDefaultTimeoutNotDerived(t *testing.T) {
+	SetDefaultTimeout(20 * time.Millisecond)
+	defer SetDefaultTimeout(0)
+
+	// an explicit timeout replaces the default
+	source := NewPromiseWithOptions(WithTimeout(time.Minute))
+
+	deadline := NewPromiseWithDeadline(time.Now().Add(time.Minute))
+	timeout := source.Timeout(time.Minute)
+	delayed := Delay(time.Minute, NewPromiseWithOptions(WithTimeout(time.Minute)).Succeed())
+	chained := source.Thenf(func() Promise { return source })
+	race := Race()
+
+	time.Sleep(50 * time.Millisecond)
+
+	assert.True(t, source.IsPending())
+	assert.True(t, deadline.IsPending())
+	assert.True(t, timeout.(Controller).IsPending())
+	assert.True(t, delayed.(Controller).IsPending())
+	assert.True(t, chained.(Controller).IsPending())
+	assert.True(t, race.(Controller).IsPending())
+
+	source.SucceedWithResult(42)
+	assert.Equal(t, 42, chained.(Controller).Result())
+}
+
+func TestThenAllWithResultsAndErrors(t *testing.T) {
+	testErr := fmt.Errorf("Testing ThenAllWithResultsAndErrors")
+
+	p1 := NewPromise().SucceedWithResult(12)
+	p2 := NewPromise().Fail(testErr)
+
+	var onSuccess int
+
+	// the promises are not required to be Controllers
+	NewPromise().Succeed().ThenAllWithResultsAndErrors(p1, promiseOnly{p2}).Success(func(result interface{}) {
+		onSuccess++
+
+		all := result.(AllResults)
+		assert.Equal(t, []interface{}{12, nil}, all.Values)
+		assert.Equal(t, []error{nil, testErr}, all.Errors)
+	})
+
+	assert.Equal(t, 1, onSuccess)
+}
+
+func TestAll(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	p := All(p1, p2)
+
+	p1.Succeed()
+	assert.True(t, p.(Controller).IsPending())
+
+	p2.Succeed()
+	assert.True(t, p.(Controller).IsSuccess())
+
+	err := fmt.Errorf("failed")
+	p = All(NewPromise(), NewPromise().Fail(err))
+	assert.Equal(t, err, p.(Controller).Error())
+
+	assert.True(t, All().(Controller).IsSuccess())
+}
+
+func TestZip(t *testing.T) {
+	a := NewPromise()
+	b := NewPromise()
+
+	p := Zip(a, b)
+
+	b.SucceedWithResult("b")
+	a.SucceedWithResult(42)
+
+	assert.Equal(t, [2]interface{}{42, "b"}, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = Zip(NewPromise(), NewPromise().Fail(err))
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestZipAll(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+	p3 := NewPromise()
+
+	p := ZipAll(p1, p2, p3)
+
+	p3.SucceedWithResult(3)
+	p1.SucceedWithResult(1)
+	p2.SucceedWithResult(2)
+
+	assert.Equal(t, []interface{}{1, 2, 3}, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = ZipAll(NewPromise(), NewPromise().Fail(err))
+	assert.Equal(t, err, p.(Controller).Error())
+
+	assert.Equal(t, []interface{}{}, ZipAll().(Controller).Result())
+}
+
+func TestRace(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	p := Race(p1, p2)
+
+	p2.SucceedWithResult(2)
+	p1.SucceedWithResult(1)
+
+	assert.Equal(t, 2, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = Race(NewPromise(), NewPromise().Fail(err))
+	assert.Equal(t, err, p.(Controller).Error())
+
+	assert.True(t, Race().(Controller).IsPending())
+}
+
+func TestThenN(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+	p3 := NewPromise()
+
+	p := NewPromise().Succeed().ThenN(2, p1, p2, p3)
+
+	p3.SucceedWithResult(3)
+	p2.Fail(fmt.Errorf("failed"))
+	assert.True(t, p.(Controller).IsPending())
+
+	p1.SucceedWithResult(1)
+	assert.Equal(t, []interface{}{3, 1}, p.(Controller).Result())
+
+	p = NewPromise().Succeed().ThenN(2, NewPromise())
+	assert.Equal(t, ErrNotEnoughPromises, p.(Controller).Error())
+
+	p = NewPromise().Succeed().ThenN(0)
+	assert.Equal(t, []interface{}{}, p.(Controller).Result())
+}
+
+func TestThenAllSettled(t *testing.T) {
+	err := fmt.Errorf("failed")
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	// the promises are not required to be Controllers
+	p := NewPromise().Succeed().ThenAllSettled(p1, promiseOnly{p2})
+
+	p2.Fail(err)
+	assert.True(t, p.(Controller).IsPending())
+
+	p1.SucceedWithResult(42)
+
+	assert.Equal(t, []PromiseSettledResult{
+		{Succeeded: true, Result: 42},
+		{Err: err},
+	}, p.(Controller).Result())
+
+	p = NewPromise().Succeed().ThenAllSettled()
+	assert.Equal(t, []PromiseSettledResult{}, p.(Controller).Result())
+}
+
+func TestCatchChain(t *testing.T) {
+	testErr := fmt.Errorf("Testing CatchChain")
+
+	var onRecover int
+	var onSuccess int
+
+	NewPromise().Fail(testErr).CatchChain(func(err error) Promise {
+		onRecover++
+		assert.Equal(t, testErr, err)
+
+		return NewPromise().SucceedWithResult(12)
+	}).Then(NewPromise().SucceedWithResult(13)).Success(func(result interface{}) {
+		onSuccess++
+		assert.Equal(t, 13, result)
+	})
+
+	assert.Equal(t, 1, onRecover)
+	assert.Equal(t, 1, onSuccess)
+}
+
+func TestCatchChainSuccess(t *testing.T) {
+	var onRecover int
+	var onSuccess int
+
+	NewPromise().SucceedWithResult(12).CatchChain(func(err error) Promise {
+		onRecover++
+		return NewPromise().Succeed()
+	}).Success(func(result interface{}) {
+		onSuccess++
+		assert.Equal(t, 12, result)
+	})
+
+	assert.Equal(t, 0, onRecover)
+	assert.Equal(t, 1, onSuccess)
+}
+
+func TestContextHandlers(t *testing.T) {
+	type ctxKey struct{}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, 12)
+
+	var onSuccess int
+	var onAlways int
+	var onCatch int
+
+	NewPromise().Succeed().SuccessCtx(ctx, func(ctx context.Context, result interface{}) {
+		onSuccess++
+		assert.Equal(t, 12, ctx.Value(ctxKey{}))
+	}).AlwaysCtx(nil, func(ctx context.Context, p Controller) {
+		onAlways++
+		assert.Equal(t, context.Background(), ctx)
+	})
+
+	NewPromise().Fail(fmt.Errorf("test")).CatchCtx(ctx, func(ctx context.Context, err error) {
+		onCatch++
+		assert.Equal(t, 12, ctx.Value(ctxKey{}))
+	})
+
+	assert.Equal(t, 1, onSuccess)
+	assert.Equal(t, 1, onAlways)
+	assert.Equal(t, 1, onCatch)
+}
+
+func TestCtxHandlersCreationContext(t *testing.T) {
+	type ctxKey struct{}
+
+	created := context.WithValue(context.Background(), ctxKey{}, "created")
+	other := context.WithValue(context.Background(), ctxKey{}, "other")
+
+	var values []interface{}
+
+	p := NewPromiseWithContext(created)
+
+	p.SuccessCtx(other, func(ctx context.Context, result interface{}) {
+		values = append(values, ctx.Value(ctxKey{}))
+	}).AlwaysCtx(nil, func(ctx context.Context, p Controller) {
+		values = append(values, ctx.Value(ctxKey{}))
+	})
+
+	p.Succeed()
+
+	NewPromiseWithOptions(WithCancelOnContext(created)).Fail(fmt.Errorf("test")).CatchCtx(nil, func(ctx context.Context, err error) {
+		values = append(values, ctx.Value(ctxKey{}))
+	})
+
+	assert.Equal(t, []interface{}{"created", "created", "created"}, values)
+}
+
+func TestProbe(t *testing.T) {
+	var polls int32
+
+	p := Probe(10*time.Millisecond, func(p Promise) bool {
+		return atomic.AddInt32(&polls, 1) == 3
+	}).(Controller)
+
+	p.Wait(make(chan Controller, 1))
+
+	assert.True(t, p.IsSuccess())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&polls))
+
+	// an interval that is not positive is clamped, rather than spinning
+	atomic.StoreInt32(&polls, 0)
+
+	p = Probe(0, func(p Promise) bool {
+		atomic.AddInt32(&polls, 1)
+		return false
+	}).(Controller)
+
+	time.Sleep(20 * time.Millisecond)
+	p.Cancel()
+
+	assert.True(t, atomic.LoadInt32(&polls) < 100)
+}
+
+func TestProbeWithResult(t *testing.T) {
+	var polls int
+
+	p := ProbeWithResult(10*time.Millisecond, func() (interface{}, bool, error) {
+		polls++
+		return polls, polls == 2, nil
+	}).(Controller)
+
+	p.Wait(make(chan Controller, 1))
+
+	assert.Equal(t, 2, p.Result())
+
+	testErr := fmt.Errorf("Testing ProbeWithResult")
+
+	p = ProbeWithResult(10*time.Millisecond, func() (interface{}, bool, error) {
+		return nil, false, testErr
+	}).(Controller)
+
+	p.Wait(make(chan Controller, 1))
+
+	assert.Equal(t, testErr, p.Error())
+}
+
+func TestThenAllLazy(t *testing.T) {
+	var created []int
+
+	factory := func(i int) Factory {
+		return func() Promise {
+			created = append(created, i)
+			return NewPromise().SucceedWithResult(i)
+		}
+	}
+
+	root := NewPromise()
+
+	var onSuccess int
+
+	root.ThenAllLazy(2, factory(1), factory(2), factory(3)).Success(func(result interface{}) {
+		onSuccess++
+		assert.Equal(t, []interface{}{1, 2, 3}, result)
+	})
+
+	// nothing is created until the root succeeds
+	assert.Empty(t, created)
+
+	root.Succeed()
+
+	assert.Equal(t, []int{1, 2, 3}, created)
+	assert.Equal(t, 1, onSuccess)
+}
+
+func TestThenAllLazyFail(t *testing.T) {
+	testErr := fmt.Errorf("Testing ThenAllLazy")
+
+	var created int
+	var onCatch int
+
+	NewPromise().Succeed().ThenAllLazy(1, func() Promise {
+		created++
+		return NewPromise().Fail(testErr)
+	}, func() Promise {
+		created++
+		return NewPromise().Succeed()
+	}).Catch(func(err error) {
+		onCatch++
+		assert.Equal(t, testErr, err)
+	})
+
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 1, onCatch)
+}
+
+func TestThenWithResultAll(t *testing.T) {
+	var onSuccess int
+
+	NewPromise().SucceedWithResult(2).ThenWithResultAll(func(result interface{}) []Promise {
+		var promises []Promise
+		for i := 0; i < result.(int); i++ {
+			promises = append(promises, NewPromise().SucceedWithResult(i))
+		}
+
+		return promises
+	}).Success(func(result interface{}) {
+		onSuccess++
+	})
+
+	assert.Equal(t, 1, onSuccess)
+}
+
+func TestThenWithResultAny(t *testing.T) {
+	testErr := fmt.Errorf("Testing ThenWithResultAny")
+
+	var onSuccess int
+	var onCatch int
+
+	NewPromise().SucceedWithResult(12).ThenWithResultAny(func(result interface{}) []Promise {
+		return []Promise{NewPromise().Fail(testErr), NewPromise().SucceedWithResult(result)}
+	}).Success(func(result interface{}) {
+		onSuccess++
+		assert.Equal(t, 12, result)
+	})
+
+	NewPromise().Succeed().ThenWithResultAny(func(result interface{}) []Promise {
+		return []Promise{NewPromise().Cancel(), NewPromise().Fail(testErr)}
+	}).Catch(func(err error) {
+		onCatch++
+		assert.Equal(t, testErr, err)
+	})
+
+	assert.Equal(t, 1, onSuccess)
+	assert.Equal(t, 1, onCatch)
+}
+
+func TestTypedPromise(t *testing.T) {
+	p := NewTypedPromise[int]()
+
+	_, ok := p.TypedResult()
+	assert.False(t, ok)
+
+	p.SucceedWithResult(12)
+
+	result, ok := p.TypedResult()
+	assert.True(t, ok)
+	assert.Equal(t, 12, result)
+
+	_, ok = Typed[string](p).TypedResult()
+	assert.False(t, ok)
+}
+
+func TestOrderedThenAll(t *testing.T) {
+	testErr := fmt.Errorf("Testing OrderedThenAll")
+
+	p1 := NewPromise()
+	p2 := NewPromise().Fail(testErr)
+	p3 := NewPromise().SucceedWithResult(12)
+
+	var onSuccess int
+	var onCatch int
+
+	NewPromise().Succeed().OrderedThenAll(p1, p2, p3).Catch(func(err error) {
+		onCatch++
+		assert.Equal(t, testErr, err)
+	})
+
+	// p2 failed, but that is not considered until p1 succeeds
+	assert.Equal(t, 0, onCatch)
+
+	p1.Succeed()
+
+	assert.Equal(t, 1, onCatch)
+
+	NewPromise().Succeed().OrderedThenAll(p1, p3).Success(func(result interface{}) {
+		onSuccess++
+		assert.Equal(t, 12, result)
+	})
+
+	assert.Equal(t, 1, onSuccess)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise().SucceedWithResult(2)
+	p3 := NewPromise().SucceedWithResult(3)
+
+	windowed := SlidingWindow(2, []Promise{p1, p2, p3})
+
+	assert.Len(t, windowed, 3)
+	assert.True(t, windowed[0].(Controller).IsPending())
+	assert.True(t, windowed[1].(Controller).IsSuccess())
+
+	// p3 is delivered, but its window waits for the first window
+	assert.True(t, windowed[2].(Controller).IsPending())
+
+	p1.SucceedWithResult(1)
+
+	assert.Equal(t, 1, windowed[0].(Controller).Result())
+	assert.Equal(t, 3, windowed[2].(Controller).Result())
+}
+
+func TestThenWithRecovery(t *testing.T) {
+	double := func(result interface{}) Promise {
+		return NewPromise().SucceedWithResult(result.(int) * 2)
+	}
+
+	recover := func(err error) Promise {
+		return NewPromise().SucceedWithResult(-1)
+	}
+
+	p := NewPromise().SucceedWithResult(21).ThenWithRecovery(double, recover)
+	assert.Equal(t, 42, p.(Controller).Result())
+
+	p = NewPromise().Fail(fmt.Errorf("failed")).ThenWithRecovery(double, recover)
+	assert.Equal(t, -1, p.(Controller).Result())
+
+	// a failure of the factory promise is not recovered
+	err := fmt.Errorf("factory failed")
+	p = NewPromise().Succeed().ThenWithRecovery(func(interface{}) Promise {
+		return NewPromise().Fail(err)
+	}, recover)
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestNewPromiseFunc(t *testing.T) {
+	p := NewPromiseFunc(func(resolve func(interface{}), reject func(error)) {
+		resolve(42)
+
+		// ignored, the promise is already resolved
+		resolve(0)
+		reject(fmt.Errorf("failed"))
+	})
+
+	assert.Equal(t, 42, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = NewPromiseFunc(func(resolve func(interface{}), reject func(error)) {
+		go reject(err)
+	})
+
+	assert.Equal(t, err, p.Wait(make(chan Controller, 1)).(Controller).Error())
+
+	p = NewPromiseFunc(func(resolve func(interface{}), reject func(error)) {
+		panic("oops")
+	})
+
+	assert.True(t, p.(Controller).IsFailed())
+}
+
+func TestThenAnyWithResult(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	p := NewPromise().Succeed().ThenAnyWithResult(p1, p2)
+
+	p1.Fail(fmt.Errorf("failed"))
+	assert.True(t, p.(Controller).IsPending())
+
+	p2.SucceedWithResult(42)
+	assert.Equal(t, 42, p.(Controller).Result())
+
+	err := fmt.Errorf("last")
+	p = NewPromise().Succeed().ThenAnyWithResult(
+		NewPromise().Fail(fmt.Errorf("first")),
+		NewPromise().Fail(err),
+	)
+
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestThenAnyWithResultf(t *testing.T) {
+	invoked := false
+
+	factory := func() []Promise {
+		invoked = true
+
+		return []Promise{
+			NewPromise().Fail(fmt.Errorf("failed")),
+			NewPromise().SucceedWithResult(42),
+		}
+	}
+
+	p := NewPromise().Fail(fmt.Errorf("root failed")).ThenAnyWithResultf(factory)
+	assert.True(t, p.(Controller).IsFailed())
+	assert.False(t, invoked)
+
+	p = NewPromise().Succeed().ThenAnyWithResultf(factory)
+	assert.True(t, invoked)
+	assert.Equal(t, 42, p.(Controller).Result())
+}
+
+func TestOnce(t *testing.T) {
+	r := NewOnceRegistry()
+
+	var onFactory int
+
+	factory := func() Promise {
+		onFactory++
+		return NewPromise()
+	}
+
+	p1 := r.Once("key", factory)
+	p1.(Controller).Succeed()
+
+	// a successful promise is kept
+	p2 := r.Once("key", factory)
+	assert.True(t, p1 == p2)
+	assert.Equal(t, 1, onFactory)
+
+	r.Reset("key")
+
+	// a failed promise is removed
+	p3 := r.Once("key", factory)
+	p3.(Controller).Fail(fmt.Errorf("failed"))
+
+	p4 := r.Once("key", factory)
+	assert.True(t, p3 != p4)
+	assert.Equal(t, 3, onFactory)
+}
+
+func TestMapN(t *testing.T) {
+	sum := func(results []interface{}) (interface{}, error) {
+		total := 0
+		for _, result := range results {
+			total += result.(int)
+		}
+
+		return total, nil
+	}
+
+	p1 := NewPromise()
+	p := MapN([]Promise{p1, NewPromise().SucceedWithResult(2), NewPromise().SucceedWithResult(3)}, sum)
+
+	assert.True(t, p.(Controller).IsPending())
+
+	p1.SucceedWithResult(1)
+	assert.Equal(t, 6, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = MapN([]Promise{NewPromise().Fail(err), NewPromise()}, sum)
+	assert.Equal(t, err, p.(Controller).Error())
+
+	p = MapN([]Promise{NewPromise().Succeed()}, func([]interface{}) (interface{}, error) {
+		return nil, err
+	})
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestFromFunc(t *testing.T) {
+	p := FromFunc(func() (int, error) {
+		return 42, nil
+	})
+
+	p.Wait(make(chan Controller, 1))
+
+	result, ok := p.TypedResult()
+	assert.True(t, ok)
+	assert.Equal(t, 42, result)
+
+	p = FromFunc(func() (int, error) {
+		panic("oops")
+	})
+
+	assert.True(t, p.Wait(make(chan Controller, 1)).(Controller).IsFailed())
+}
+
+func TestFromFuncCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := FromFuncCtx(ctx, func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "done", nil
+	})
+
+	cancel()
+
+	assert.True(t, p.Wait(make(chan Controller, 1)).(Controller).IsCanceled())
+}
+
+func TestUnwrap(t *testing.T) {
+	p := NewPromise()
+
+	go p.SucceedWithResult(42)
+
+	result, err := Unwrap(p)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result)
+
+	result, err = Unwrap(NewPromise().Fail(fmt.Errorf("failed")))
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestToFuture(t *testing.T) {
+	p := NewPromise()
+	f := p.ToFuture()
+
+	go p.SucceedWithResult(42)
+
+	result, err := f.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result)
+
+	typed := NewTypedPromise[string]()
+	typed.SucceedWithResult("done")
+
+	value, err := ToTypedFuture[string](typed).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "done", value)
+}
+
+func TestFactoryFailNTimes(t *testing.T) {
+	factory := FactoryFailNTimes(2, func() Promise {
+		return NewPromise().SucceedWithResult(42)
+	})
+
+	assert.Equal(t, ErrInjectedFailure, factory().(Controller).Error())
+	assert.Equal(t, ErrInjectedFailure, factory().(Controller).Error())
+	assert.Equal(t, 42, factory().(Controller).Result())
+	assert.Equal(t, 42, factory().(Controller).Result())
+}
+
+func TestNewRetryWithPolicy(t *testing.T) {
+	factory := FactoryFailNTimes(2, func() Promise {
+		return NewPromise().SucceedWithResult(42)
+	})
+
+	p := NewRetryWithPolicy(ConstantBackoff(time.Millisecond, 2), factory)
+	assert.Equal(t, 42, p.Wait(make(chan Controller, 1)).(Controller).Result())
+
+	factory = FactoryFailNTimes(3, func() Promise {
+		return NewPromise().SucceedWithResult(42)
+	})
+
+	p = NewRetryWithPolicy(LinearBackoff(time.Millisecond, time.Millisecond, 2), factory)
+	assert.Equal(t, ErrInjectedFailure, p.Wait(make(chan Controller, 1)).(Controller).Error())
+
+	// a canceled attempt is not retried
+	var attempts int64
+	p = NewRetryWithPolicy(ConstantBackoff(time.Millisecond, 2), func() Promise {
+		atomic.AddInt64(&attempts, 1)
+		return NewPromise().Cancel()
+	})
+	assert.True(t, p.(Controller).IsCanceled())
+	assert.Equal(t, int64(1), atomic.LoadInt64(&attempts))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	policy := ExponentialBackoff(time.Millisecond, 5*time.Millisecond, 2, false)
+
+	delay, ok := policy.NextDelay(1, nil)
+	assert.True(t, ok)
+	assert.Equal(t, time.Millisecond, delay)
+
+	delay, _ = policy.NextDelay(2, nil)
+	assert.Equal(t, 2*time.Millisecond, delay)
+
+	delay, _ = policy.NextDelay(10, nil)
+	assert.Equal(t, 5*time.Millisecond, delay)
+}
+
+func TestCached(t *testing.T) {
+	var onFactory int
+
+	factory := Cached(50*time.Millisecond, func() Promise {
+		onFactory++
+		return NewPromise().SucceedWithResult(onFactory)
+	})
+
+	assert.Equal(t, 1, factory().(Controller).Result())
+	assert.Equal(t, 1, factory().(Controller).Result())
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Equal(t, 2, factory().(Controller).Result())
+
+	// failures are not cached
+	failures := Cached(time.Minute, func() Promise {
+		onFactory++
+		return NewPromise().Fail(fmt.Errorf("failed"))
+	})
+
+	failures()
+	failures()
+	assert.Equal(t, 4, onFactory)
+}
+
+func TestCachedWithKey(t *testing.T) {
+	var key string
+	var onFactory int
+
+	factory := CachedWithKey(time.Minute, func() string { return key }, func() Promise {
+		onFactory++
+		return NewPromise().SucceedWithResult(key)
+	})
+
+	key = "a"
+	assert.Equal(t, "a", factory().(Controller).Result())
+
+	key = "b"
+	assert.Equal(t, "b", factory().(Controller).Result())
+
+	key = "a"
+	assert.Equal(t, "a", factory().(Controller).Result())
+	assert.Equal(t, 2, onFactory)
+}
+
+func TestCachedSweep(t *testing.T) {
+	c := &promiseCache{ttl: 10 * time.Millisecond, entries: make(map[string]*cacheEntry)}
+
+	factory := func() Promise {
+		return NewPromise().Succeed()
+	}
+
+	c.get("a", factory)
+	c.get("b", factory)
+	assert.Len(t, c.entries, 2)
+
+	time.Sleep(20 * time.Millisecond)
+
+	// the expired entries are removed when another key is cached
+	c.get("c", factory)
+	assert.Len(t, c.entries, 1)
+	assert.NotNil(t, c.entries["c"])
+}
+
+func TestThenAllWithResults(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	p := NewPromise().Succeed().ThenAllWithResults(p1, p2)
+
+	p2.SucceedWithResult(2)
+	p1.SucceedWithResult(1)
+
+	assert.Equal(t, []interface{}{1, 2}, p.(Controller).Result())
+
+	p1 = NewPromise()
+	p2 = NewPromise()
+	err := fmt.Errorf("failed")
+
+	p = NewPromise().Succeed().ThenAllWithResults(p1, p2)
+
+	p2.Fail(err)
+
+	assert.Equal(t, err, p.(Controller).Error())
+	assert.True(t, p1.IsPending())
+}
+
+func TestThenAllWithResultsCanceled(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	p := NewPromise().Succeed().ThenAllWithResultsCanceled(p1, p2)
+
+	p2.SucceedWithResult(2)
+	p1.SucceedWithResult(1)
+
+	assert.Equal(t, []interface{}{1, 2}, p.(Controller).Result())
+
+	p1 = NewPromise()
+	p2 = NewPromise()
+	err := fmt.Errorf("failed")
+
+	p = NewPromise().Succeed().ThenAllWithResultsCanceled(p1, p2)
+
+	p2.Fail(err)
+
+	assert.Equal(t, err, p.(Controller).Error())
+	assert.True(t, p1.IsCanceled())
+}
+
+func TestLatch(t *testing.T) {
+	p := NewPromise()
+	latch := Latch(p)
+
+	l1 := latch()
+	l2 := latch()
+
+	assert.True(t, l1 == l2)
+	assert.True(t, l1.(Controller).IsPending())
+
+	p.SucceedWithResult(42)
+
+	assert.Equal(t, 42, latch().(Controller).Result())
+}
+
+func TestMultiSend(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise().SucceedWithResult(1)
+
+	MultiSend(42, p1, p2)
+
+	assert.Equal(t, 42, p1.Result())
+	assert.Equal(t, 1, p2.Result())
+
+	p3 := NewPromise()
+	p4 := NewPromise()
+
+	MultiSendConcurrent(42, p3, p4)
+
+	assert.Equal(t, 42, p3.Result())
+	assert.Equal(t, 42, p4.Result())
+
+	err := fmt.Errorf("failed")
+	p5 := NewPromise()
+	p6 := NewPromise()
+
+	MultiFail(err, p5, p6)
+
+	assert.Equal(t, err, p5.Error())
+	assert.Equal(t, err, p6.Error())
+
+	p7 := NewPromise()
+	MultiSucceed(p7)
+	assert.True(t, p7.IsSuccess())
+}
+
+func TestThenPair(t *testing.T) {
+	add := func(a, b interface{}) Promise {
+		return NewPromise().SucceedWithResult(a.(int) + b.(int))
+	}
+
+	p1 := NewPromise()
+	p2 := NewPromise().SucceedWithResult(2)
+
+	p := p1.ThenPair(p2, add)
+	p1.SucceedWithResult(40)
+
+	assert.Equal(t, 42, p.(Controller).Result())
+
+	// other is not required to be a Controller
+	p = p1.ThenPair(promiseOnly{p2}, add)
+	assert.Equal(t, 42, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = NewPromise().Succeed().ThenPair(NewPromise().Fail(err), add)
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestResolveAll(t *testing.T) {
+	promises := ResolveAll([]interface{}{1, "two"})
+
+	assert.Len(t, promises, 2)
+	assert.Equal(t, 1, promises[0].Result())
+	assert.Equal(t, "two", promises[1].Result())
+
+	typed := ResolveAllTyped([]int{1, 2, 3})
+
+	assert.Len(t, typed, 3)
+
+	result, ok := typed[2].TypedResult()
+	assert.True(t, ok)
+	assert.Equal(t, 3, result)
+}
+
+func TestWithHandlerOrder(t *testing.T) {
+	var order []int
+
+	p := NewPromise().WithHandlerOrder(LIFO)
+
+	p.Always(func(Controller) { order = append(order, 1) })
+	p.Always(func(Controller) { order = append(order, 2) })
+	p.Always(func(Controller) { order = append(order, 3) })
+
+	p.Succeed()
+
+	assert.Equal(t, []int{3, 2, 1}, order)
+
+	order = nil
+	p = NewPromise()
+
+	p.Success(func(interface{}) { order = append(order, 1) })
+	p.Success(func(interface{}) { order = append(order, 2) })
+
+	p.Succeed()
+
+	assert.Equal(t, []int{1, 2}, order)
+}
+
+func TestStabilize(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+	p3 := NewPromise()
+
+	p := Stabilize(2, 20*time.Millisecond, []Promise{p1, p2, p3})
+
+	p1.SucceedWithResult(1)
+	p2.SucceedWithResult(2)
+
+	assert.Equal(t, []interface{}{1, 2}, p.Wait(make(chan Controller, 1)).(Controller).Result())
+
+	// a success within the window is not included in the results
+	p1 = NewPromise()
+	p2 = NewPromise()
+	p3 = NewPromise()
+
+	p = Stabilize(2, 20*time.Millisecond, []Promise{p1, p2, p3})
+
+	p1.SucceedWithResult(1)
+	p2.SucceedWithResult(2)
+	p3.SucceedWithResult(3)
+
+	assert.Equal(t, []interface{}{1, 2}, p.Wait(make(chan Controller, 1)).(Controller).Result())
+
+	// a failure within the window resets the quorum
+	p1 = NewPromise()
+	p2 = NewPromise()
+	p3 = NewPromise()
+	err := fmt.Errorf("failed")
+
+	p = Stabilize(2, time.Minute, []Promise{p1, p2, p3})
+
+	p1.Succeed()
+	p2.Succeed()
+	p3.Fail(err)
+
+	assert.Equal(t, err, p.(Controller).Error())
+
+	// a failure before the quorum is reached does not reset the count
+	p1 = NewPromise()
+	p2 = NewPromise()
+	p3 = NewPromise()
+
+	p = Stabilize(2, 10*time.Millisecond, []Promise{p1, p2, p3})
+
+	p1.SucceedWithResult(1)
+	p2.Fail(err)
+	p3.SucceedWithResult(3)
+
+	assert.Equal(t, []interface{}{1, 3}, p.Wait(make(chan Controller, 1)).(Controller).Result())
+
+	// the handlers of the returned promise may deliver another input
+	p1 = NewPromise()
+	p2 = NewPromise()
+
+	p = Stabilize(2, time.Minute, []Promise{p1, p2}).Catch(func(error) {
+		p2.Cancel()
+	})
+
+	p1.Fail(err)
+
+	assert.Equal(t, err, p.(Controller).Error())
+	assert.True(t, p2.IsCanceled())
+}
+
+func TestInterrupt(t *testing.T) {
+	p := NewPromise()
+	signal := NewPromise()
+
+	interrupted := Interrupt(p, signal)
+
+	p.SucceedWithResult(42)
+	signal.Succeed()
+
+	assert.Equal(t, 42, interrupted.(Controller).Result())
+
+	p = NewPromise()
+	signal = NewPromise()
+
+	interrupted = Interrupt(p, signal)
+
+	signal.Fail(fmt.Errorf("failed"))
+
+	assert.True(t, interrupted.(Controller).IsCanceled())
+	assert.True(t, p.IsCanceled())
+}
+
+func TestThenAllWithResultsMap(t *testing.T) {
+	names := []string{"first", "second", "first"}
+
+	keyFn := func(i int, result interface{}) string {
+		return names[i]
+	}
+
+	p := NewPromise().Succeed().ThenAllWithResultsMap(keyFn,
+		NewPromise().SucceedWithResult(1),
+		NewPromise().SucceedWithResult(2),
+		NewPromise().SucceedWithResult(3))
+
+	// the later result overwrites the earlier one
+	assert.Equal(t, map[string]interface{}{"first": 3, "second": 2}, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = NewPromise().Succeed().ThenAllWithResultsMap(keyFn, NewPromise().Fail(err))
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestThenChain(t *testing.T) {
+	increment := func(result interface{}) Promise {
+		return NewPromise().SucceedWithResult(result.(int) + 1)
+	}
+
+	p := NewPromise().SucceedWithResult(1).ThenChain(increment, increment, increment)
+	assert.Equal(t, 4, p.(Controller).Result())
+
+	p = NewPromise().SucceedWithResult(1).ThenChain()
+	assert.Equal(t, 1, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = NewPromise().SucceedWithResult(1).ThenChain(increment, func(interface{}) Promise {
+		return NewPromise().Fail(err)
+	}, increment)
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestWrap(t *testing.T) {
+	p := NewPromise()
+	c := Wrap(p)
+
+	assert.True(t, c.IsPending())
+
+	p.SucceedWithResult(42)
+	assert.Equal(t, 42, c.Result())
+
+	// delivering the wrapper first does not deliver the promise
+	p = NewPromise()
+	c = Wrap(p).Cancel()
+
+	p.Succeed()
+	assert.True(t, c.IsCanceled())
+}
+
+func TestAtomicResult(t *testing.T) {
+	p := NewTypedPromise[int]()
+	r := NewAtomicResult[int](p)
+
+	_, ok := r.Get()
+	assert.False(t, ok)
+	assert.Equal(t, -1, r.GetOrDefault(-1))
+
+	p.SucceedWithResult(42)
+
+	result, ok := r.Get()
+	assert.True(t, ok)
+	assert.Equal(t, 42, result)
+	assert.Equal(t, 42, r.GetOrDefault(-1))
+}
+
+func TestThenWithResultMap(t *testing.T) {
+	p := NewPromise().SucceedWithResult(2).ThenWithResultMap(func(result interface{}) map[string]Promise {
+		return map[string]Promise{
+			"double": NewPromise().SucceedWithResult(result.(int) * 2),
+			"square": NewPromise().SucceedWithResult(result.(int) * result.(int)),
+		}
+	})
+
+	assert.Equal(t, map[string]interface{}{"double": 4, "square": 4}, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = NewPromise().Succeed().ThenWithResultMap(func(interface{}) map[string]Promise {
+		return map[string]Promise{"failed": NewPromise().Fail(err)}
+	})
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestGroup(t *testing.T) {
+	var g Group
+
+	p1 := NewPromise()
+	err := fmt.Errorf("failed")
+
+	g.Add(p1)
+	g.Add(NewPromise().Fail(err))
+
+	wait := g.Wait()
+	results := g.Results()
+
+	assert.True(t, wait.(Controller).IsPending())
+
+	p1.SucceedWithResult(42)
+
+	assert.True(t, wait.(Controller).IsSuccess())
+	assert.Equal(t, []SettledResult{
+		{Succeeded: true, Result: 42},
+		{Err: err},
+	}, results.(Controller).Result())
+}
+
+func TestGroupCancel(t *testing.T) {
+	var g Group
+
+	p1 := NewPromise()
+	p2 := NewPromise().Succeed()
+
+	g.Add(p1)
+	g.Add(p2)
+	g.Cancel()
+
+	assert.True(t, p1.IsCanceled())
+	assert.True(t, p2.IsSuccess())
+}
+
+func TestThenWithTimeout(t *testing.T) {
+	slow := NewPromise()
+
+	p := NewPromise().Succeed().ThenWithTimeout(20*time.Millisecond, func(interface{}) Promise {
+		return slow
+	})
+
+	assert.Equal(t, ErrPromiseTimeout, p.Wait(make(chan Controller, 1)).(Controller).Error())
+	assert.True(t, slow.IsPending())
+
+	p = NewPromise().SucceedWithResult(21).ThenWithTimeout(time.Minute, func(result interface{}) Promise {
+		return NewPromise().SucceedWithResult(result.(int) * 2)
+	})
+
+	assert.Equal(t, 42, p.(Controller).Result())
+}
+
+func TestThenfWithTimeout(t *testing.T) {
+	var invoked int64
+
+	slow := NewPromise()
+
+	p := NewPromise().Succeed().ThenfWithTimeout(20*time.Millisecond, func() Promise {
+		return slow
+	})
+
+	assert.Equal(t, ErrPromiseTimeout, p.Wait(make(chan Controller, 1)).(Controller).Error())
+	assert.True(t, slow.IsPending())
+
+	err := fmt.Errorf("failed")
+	p = NewPromise().Fail(err).ThenfWithTimeout(time.Minute, func() Promise {
+		atomic.AddInt64(&invoked, 1)
+		return slow
+	})
+
+	assert.Equal(t, err, p.(Controller).Error())
+	assert.Equal(t, int64(0), atomic.LoadInt64(&invoked))
+}
+
+type notFoundError struct {
+	key string
+}
+
+func (e *notFoundError) Error() string {
+	return "not found: " + e.key
+}
+
+func TestIgnoreErrors(t *testing.T) {
+	errNotFound := fmt.Errorf("not found")
+
+	p := NewPromise().Fail(fmt.Errorf("wrapped: %w", errNotFound)).IgnoreErrors(errNotFound)
+	assert.True(t, p.(Controller).IsSuccess())
+	assert.Nil(t, p.(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	p = NewPromise().Fail(err).IgnoreErrors(errNotFound)
+	assert.Equal(t, err, p.(Controller).Error())
+
+	p = NewPromise().SucceedWithResult(42).IgnoreErrors(errNotFound)
+	assert.Equal(t, 42, p.(Controller).Result())
+}
+
+func TestIgnoreErrorTypes(t *testing.T) {
+	p := NewPromise().Fail(&notFoundError{key: "a"}).IgnoreErrorTypes(new(*notFoundError))
+	assert.True(t, p.(Controller).IsSuccess())
+
+	err := fmt.Errorf("failed")
+	p = NewPromise().Fail(err).IgnoreErrorTypes(new(*notFoundError))
+	assert.Equal(t, err, p.(Controller).Error())
+
+	// a value of the type is also accepted
+	p = NewPromise().Fail(&notFoundError{key: "a"}).IgnoreErrorTypes(&notFoundError{})
+	assert.True(t, p.(Controller).IsSuccess())
+
+	assert.Panics(t, func() {
+		NewPromise().IgnoreErrorTypes(new(string))
+	})
+
+	assert.Panics(t, func() {
+		NewPromise().IgnoreErrorTypes(nil)
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	inner := NewPromise().SucceedWithResult(42)
+	middle := NewPromise().SucceedWithResult(inner)
+	outer := NewPromise().SucceedWithResult(middle)
+
+	assert.True(t, inner == Flatten(outer).(Controller).Result())
+	assert.Equal(t, 42, Flatten2(outer).(Controller).Result())
+	assert.Equal(t, 42, FlattenN(-1, outer).(Controller).Result())
+
+	err := fmt.Errorf("failed")
+	failed := NewPromise().SucceedWithResult(NewPromise().Fail(err))
+	assert.Equal(t, err, FlattenN(-1, failed).(Controller).Error())
+}
+
+func TestThenSwitchAll(t *testing.T) {
+	selector := func(result interface{}) string {
+		return result.(string)
+	}
+
+	cases := map[string]FactoryWithResult{
+		"a": func(interface{}) Promise { return NewPromise().SucceedWithResult(1) },
+		"b": func(interface{}) Promise { return NewPromise().SucceedWithResult(2) },
+	}
+
+	defaultCase := func(interface{}) Promise {
+		return NewPromise().SucceedWithResult(0)
+	}
+
+	p := NewPromise().SucceedWithResult("b").ThenSwitchAll(selector, cases, defaultCase, nil)
+	assert.Equal(t, 2, p.(Controller).Result())
+
+	p = NewPromise().SucceedWithResult("c").ThenSwitchAll(selector, cases, defaultCase, nil)
+	assert.Equal(t, 0, p.(Controller).Result())
+
+	p = NewPromise().SucceedWithResult("c").ThenSwitchAll(selector, cases, nil, nil)
+	assert.Equal(t, ErrNoMatchingCase, p.(Controller).Error())
+
+	// the result of the selected case feeds the shared merge promises
+	merge := func(result interface{}) []Promise {
+		return []Promise{
+			NewPromise().SucceedWithResult(result.(int) * 10),
+			NewPromise().SucceedWithResult(result.(int) + 1),
+		}
+	}
+
+	p = NewPromise().SucceedWithResult("b").ThenSwitchAll(selector, cases, defaultCase, merge)
+	assert.Equal(t, []interface{}{20, 3}, p.(Controller).Result())
+
+	p = NewPromise().SucceedWithResult("c").ThenSwitchAll(selector, cases, defaultCase, merge)
+	assert.Equal(t, []interface{}{0, 1}, p.(Controller).Result())
+
+	err := fmt.Errorf("merge failed")
+	p = NewPromise().SucceedWithResult("a").ThenSwitchAll(selector, cases, nil, func(interface{}) []Promise {
+		return []Promise{NewPromise().Succeed(), NewPromise().Fail(err)}
+	})
+	assert.Equal(t, err, p.(Controller).Error())
+}
+
+func TestSuccessPriority(t *testing.T) {
+	var order []int
+
+	p := NewPromise()
+
+	p.Success(func(interface{}) { order = append(order, 0) })
+	p.SuccessPriority(-1, func(interface{}) { order = append(order, -1) })
+	p.SuccessPriority(10, func(interface{}) { order = append(order, 10) })
+	p.WithPriority(5).Always(func(Controller) { order = append(order, 5) })
+
+	p.Succeed()
+
+	assert.Equal(t, []int{10, 0, -1, 5}, order)
+
+	// priority applies within each handler type
+	order = nil
+	p = NewPromise()
+
+	p.WithPriority(1).Always(func(Controller) { order = append(order, 1) })
+	p.WithPriority(2).Always(func(Controller) { order = append(order, 2) })
+
+	p.Succeed()
+
+	assert.Equal(t, []int{2, 1}, order)
+}
+
+func TestWithPriorityViews(t *testing.T) {
+	var order []int
+
+	p := NewPromise()
+
+	p.SuccessCtx(nil, func(context.Context, interface{}) { order = append(order, 0) })
+	p.WithPriority(1).SuccessCtx(nil, func(context.Context, interface{}) { order = append(order, 1) })
+	p.WithPriority(2).DebugMode().Success(func(interface{}) { order = append(order, 2) })
+	p.WithPriority(3).WithMaxHandlers(0).Success(func(interface{}) { order = append(order, 3) })
+	p.WithPriority(4).WithHandlerOrder(FIFO).Success(func(interface{}) { order = append(order, 4) })
+
+	p.Succeed()
+
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, order)
+
+	// the priority of the *Ctx forms applies within each handler type
+	order = nil
+	p = NewPromise()
+
+	p.WithPriority(1).AlwaysCtx(nil, func(context.Context, Controller) { order = append(order, 1) })
+	p.WithPriority(2).AlwaysCtx(nil, func(context.Context, Controller) { order = append(order, 2) })
+	p.WithPriority(1).CatchCtx(nil, func(context.Context, error) { order = append(order, -1) })
+	p.WithPriority(2).CatchCtx(nil, func(context.Context, error) { order = append(order, -2) })
+
+	p.Fail(fmt.Errorf("failed"))
+
+	assert.Equal(t, []int{-2, -1, 2, 1}, order)
+}
+
+func TestCorrelate(t *testing.T) {
+	p := NewPromise()
+
+	assert.Equal(t, "", CorrelationID(p))
+
+	c := Correlate("request-1", p)
+
+	assert.Equal(t, "request-1", CorrelationID(c))
+	assert.Equal(t, "", CorrelationID(p))
+
+	p.SucceedWithResult(42)
+	assert.Equal(t, 42, c.(Controller).Result())
+
+	// shared promises are not modified
+	c = Correlate("request-2", All())
+
+	assert.Equal(t, "request-2", CorrelationID(c))
+	assert.True(t, c.(Controller).IsSuccess())
+	assert.Equal(t, "", CorrelationID(Any()))
+}
+
+func TestNewErrorPromise(t *testing.T) {
+	p := NewErrorPromise("user %d not found", 42)
+
+	assert.True(t, p.IsFailed())
+	assert.Equal(t, "user 42 not found", p.Error().Error())
+
+	err := fmt.Errorf("failed")
+	p = NewErrorfPromise("wrapped: %w", err)
+
+	assert.ErrorIs(t, p.Error(), err)
+}
+
+func TestMapError(t *testing.T) {
+	errNotFound := fmt.Errorf("not found")
+	errMissing := fmt.Errorf("missing")
+
+	p := NewPromise().Fail(errNotFound).MapError(func(err error) error {
+		return errMissing
+	})
+
+	err := p.(Controller).Error()
+	assert.Equal(t, "missing", err.Error())
+	assert.ErrorIs(t, err, errMissing)
+	assert.ErrorIs(t, err, errNotFound)
+
+	p = NewPromise().SucceedWithResult(42).MapError(func(err error) error {
+		return errMissing
+	})
+	assert.Equal(t, 42, p.(Controller).Result())
+
+	p = NewPromise().Cancel().MapError(func(err error) error {
+		return errMissing
+	})
+	assert.True(t, p.(Controller).IsCanceled())
+
+	// errors of an uncomparable type must not panic
+	p = NewPromise().Fail(listError{"a"}).MapError(func(err error) error {
+		return listError{"b"}
+	})
+
+	err = p.(Controller).Error()
+	assert.Equal(t, "[b]", err.Error())
+	assert.ErrorAs(t, err, &listError{})
+}
+
+// listError is an error of an uncomparable type
+type listError []string
+
+func (e listError) Error() string {
+	return fmt.Sprint([]string(e))
+}
+
+func TestThenWithResultCatch(t *testing.T) {
+	factory := func(result interface{}) Promise {
+		return NewPromise().SucceedWithResult("success")
+	}
+
+	catch := func(err error) Promise {
+		return NewPromise().SucceedWithResult("caught")
+	}
+
+	p := NewPromise().Succeed().ThenWithResultCatch(factory, catch)
+	assert.Equal(t, "success", p.(Controller).Result())
+
+	p = NewPromise().Fail(fmt.Errorf("failed")).ThenWithResultCatch(factory, catch)
+	assert.Equal(t, "caught", p.(Controller).Result())
+}
+
+func TestNewPromiseSynchronous(t *testing.T) {
+	var onSuccess, onAlways int
+
+	p := NewPromise()
+
+	p.Success(func(interface{}) { onSuccess++ })
+	p.Always(func(Controller) { onAlways++ })
+
+	p.SucceedWithResult(42)
+
+	// no wait is required, the handlers ran before delivery returned
+	assert.Equal(t, 1, onSuccess)
+	assert.Equal(t, 1, onAlways)
+}
+
+func TestWhenN(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+	p3 := NewPromise()
+
+	p := WhenN(2, p1, p2, p3)
+
+	p3.SucceedWithResult(3)
+	assert.True(t, p.(Controller).IsPending())
+
+	p1.SucceedWithResult(1)
+	assert.Equal(t, []interface{}{3, 1}, p.(Controller).Result())
+
+	err1 := fmt.Errorf("failed 1")
+	err2 := fmt.Errorf("failed 2")
+
+	p = WhenN(2, NewPromise().Fail(err1), NewPromise().Fail(err2), NewPromise())
+
+	err := p.(Controller).Error()
+	assert.ErrorIs(t, err, err1)
+	assert.ErrorIs(t, err, err2)
+
+	p = WhenN(3, NewPromise(), NewPromise())
+	assert.Equal(t, ErrNotEnoughPromises, p.(Controller).Error())
+}
+
+func TestWhenNReentrant(t *testing.T) {
+	a := NewPromise()
+	b := NewPromise()
+
+	// the success handler delivers another input of WhenN
+	p := WhenN(1, a, b).Success(func(interface{}) {
+		b.Cancel()
+	})
+
+	a.SucceedWithResult(42)
+
+	assert.Equal(t, []interface{}{42}, p.(Controller).Result())
+	assert.True(t, b.IsCanceled())
+}
+
+func TestDeliverTimeout(t *testing.T) {
+	p := DeliverTimeout(20*time.Millisecond, 42)
+
+	assert.True(t, p.IsPending())
+	assert.Equal(t, 42, p.Wait(make(chan Controller, 1)).(Controller).Result())
+
+	p = DeliverTimeout(time.Millisecond, ErrPromiseTimeout)
+	assert.Equal(t, ErrPromiseTimeout, p.Wait(make(chan Controller, 1)).(Controller).Error())
+}
+
+func TestDeliverTimeoutRace(t *testing.T) {
+	p := Race(DeliverTimeout(20*time.Millisecond, ErrPromiseTimeout), NewPromise())
+
+	runtime.GC()
+
+	assert.Equal(t, ErrPromiseTimeout, p.Wait(make(chan Controller, 1)).(Controller).Error())
+}
+
+func TestConvertError(t *testing.T) {
+	errNoRows := fmt.Errorf("no rows")
+	errUserNotFound := fmt.Errorf("user not found")
+
+	p := NewPromise().Fail(fmt.Errorf("query: %w", errNoRows)).ConvertError(errNoRows, errUserNotFound)
+	assert.Equal(t, errUserNotFound, p.(Controller).Error())
+
+	err := fmt.Errorf("failed")
+	p = NewPromise().Fail(err).ConvertError(errNoRows, errUserNotFound)
+	assert.Equal(t, err, p.(Controller).Error())
+
+	p = NewPromise().SucceedWithResult(42).ConvertErrors(map[error]error{errNoRows: errUserNotFound})
+	assert.Equal(t, 42, p.(Controller).Result())
+}
+
+func TestThenWithResultRetry(t *testing.T) {
+	var attempts int64
+
+	factory := func(result interface{}) Promise {
+		if atomic.AddInt64(&attempts, 1) < 3 {
+			return NewPromise().Fail(fmt.Errorf("failed"))
+		}
+
+		return NewPromise().SucceedWithResult(result.(int) * 2)
+	}
+
+	p := NewPromise().SucceedWithResult(21).ThenWithResultRetry(2, factory)
+	assert.Equal(t, 42, p.Wait(make(chan Controller, 1)).(Controller).Result())
+
+	atomic.StoreInt64(&attempts, 0)
+
+	p = NewPromise().SucceedWithResult(21).ThenWithResultRetry(1, factory)
+	assert.True(t, p.Wait(make(chan Controller, 1)).(Controller).IsFailed())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&attempts))
+}
+
+func TestAggregate(t *testing.T) {
+	sum := func(acc, result interface{}) interface{} {
+		return acc.(int) + result.(int)
+	}
+
+	promises := []Promise{
+		NewPromise().SucceedWithResult(1),
+		NewPromise().Fail(fmt.Errorf("failed")),
+		NewPromise().SucceedWithResult(2),
+	}
+
+	// accept partial failures
+	p := Aggregate(promises, 10, sum, func(MultiError) error { return nil })
+	assert.Equal(t, 13, p.(Controller).Result())
+
+	err := fmt.Errorf("too many failures")
+	p = Aggregate(promises, 10, sum, func(e MultiError) error {
+		assert.Len(t, e.Errors, 1)
+		return err
+	})
+	assert.Equal(t, err, p.(Controller).Error())
+
+	p = Aggregate(promises, 10, sum, nil)
+	assert.IsType(t, &MultiError{}, p.(Controller).Error())
+
+	// the promises are not required to be Controllers
+	p = Aggregate([]Promise{promiseOnly{promises[0]}, promises[2]}, 10, sum, nil)
+	assert.Equal(t, 13, p.(Controller).Result())
+}
+
+func TestThenWithResultWindow(t *testing.T) {
+	sum := func(result interface{}) Promise {
+		total := 0
+		for _, value := range result.([]interface{}) {
+			total += value.(int)
+		}
+
+		return NewPromise().SucceedWithResult(total)
+	}
+
+	p := NewPromise().SucceedWithResult([]interface{}{1, 2, 3, 4, 5}).ThenWithResultWindow(2, sum)
+	assert.Equal(t, []interface{}{3, 7, 5}, p.(Controller).Result())
+
+	p = NewPromise().SucceedWithResult(42).ThenWithResultWindow(2, sum)
+	assert.Equal(t, ErrResultNotSlice, p.(Controller).Error())
+}
+
+func TestShared(t *testing.T) {
+	p := NewPromise()
+	shared := Shared(p)
+
+	var results []interface{}
+
+	c1 := shared.ThenWithResult(func(result interface{}) Promise {
+		return NewPromise().SucceedWithResult(result.(int) + 1)
+	})
+
+	c2 := shared.ThenWithResult(func(result interface{}) Promise {
+		return NewPromise().Fail(fmt.Errorf("failed"))
+	})
+
+	shared.Success(func(result interface{}) {
+		results = append(results, result)
+	})
+
+	p.SucceedWithResult(1)
+
+	assert.Equal(t, 2, c1.(Controller).Result())
+	assert.True(t, c2.(Controller).IsFailed())
+	assert.Equal(t, []interface{}{1}, results)
+
+	// canceling the shared promise does not cancel the original
+	p = NewPromise()
+	Shared(p).(Controller).Cancel()
+	assert.True(t, p.IsPending())
+}
+
+func TestDelay(t *testing.T) {
+	p := NewPromise()
+	delayed := Delay(20*time.Millisecond, p)
+
+	start := time.Now()
+	p.SucceedWithResult(42)
+
+	assert.True(t, delayed.(Controller).IsPending())
+	assert.Equal(t, 42, delayed.Wait(make(chan Controller, 1)).(Controller).Result())
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
+
+func TestJitter(t *testing.T) {
+	start := time.Now()
+
+	p := Jitter(10*time.Millisecond, 20*time.Millisecond, NewPromise().SucceedWithResult(42))
+
+	assert.Equal(t, 42, p.Wait(make(chan Controller, 1)).(Controller).Result())
+	assert.True(t, time.Since(start) >= 10*time.Millisecond)
+}
+
+func TestThenRetryWithBackoff(t *testing.T) {
+	var attempts int64
+
+	factory := func(result interface{}) Promise {
+		if atomic.AddInt64(&attempts, 1) < 3 {
+			return NewPromise().Fail(fmt.Errorf("failed"))
+		}
+
+		return NewPromise().SucceedWithResult(result)
+	}
+
+	p := NewPromise().SucceedWithResult(42).ThenRetryWithBackoff(3, time.Millisecond, 2, factory)
+	assert.Equal(t, 42, p.Wait(make(chan Controller, 1)).(Controller).Result())
+
+	atomic.StoreInt64(&attempts, 0)
+
+	p = NewPromise().SucceedWithResult(42).ThenRetryWithBackoff(2, time.Millisecond, 2, factory)
+	assert.True(t, p.Wait(make(chan Controller, 1)).(Controller).IsFailed())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&attempts))
+}
+
+func TestThenRetryWithBackoffContext(t *testing.T) {
+	var attempts int64
+
+	started := make(chan struct{}, 1)
+	factory := func(interface{}) Promise {
+		atomic.AddInt64(&attempts, 1)
+
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+
+		return NewPromise().Fail(fmt.Errorf("failed"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source := NewPromiseWithContext(ctx).SucceedWithResult(42)
+
+	p := source.ThenRetryWithBackoff(100, 10*time.Millisecond, 1, factory)
+
+	<-started
+	cancel()
+
+	assert.True(t, p.Wait(make(chan Controller, 1)).(Controller).IsCanceled())
+
+	// no further attempts are made once the context is done
+	made := atomic.LoadInt64(&attempts)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, made, atomic.LoadInt64(&attempts))
+}
+
+func TestNewPromisePair(t *testing.T) {
+	producer, consumer := NewPromisePair()
+
+	var result interface{}
+	consumer.Success(func(r interface{}) {
+		result = r
+	})
+
+	producer.SucceedWithResult(42)
+
+	assert.Equal(t, 42, result)
+}
+
+func TestThenWithResultOrTimeout(t *testing.T) {
+	work := NewPromise()
+	timeout := NewPromise()
+
+	p := NewPromise().Succeed().ThenWithResultOrTimeout(0, func(interface{}) Promise {
+		return work
+	}, timeout)
+
+	timeout.Succeed()
+	work.SucceedWithResult(42)
+
+	assert.Equal(t, ErrPromiseTimeout, p.(Controller).Error())
+
+	p = NewPromise().Succeed().ThenWithResultOrTimeout(time.Minute, func(interface{}) Promise {
+		return NewPromise().SucceedWithResult(42)
+	}, nil)
+
+	assert.Equal(t, 42, p.(Controller).Result())
+}
+
+func TestLoopUntil(t *testing.T) {
+	var count int
+
+	p := LoopUntil(func() Promise {
+		count++
+		return NewPromise().SucceedWithResult(count)
+	}, func(result interface{}, err error) bool {
+		return result.(int) == 10000
+	})
+
+	// synchronous deliveries do not recurse for each iteration
+	assert.Equal(t, 10000, p.(Controller).Result())
+
+	count = 0
+
+	p = LoopUntil(func() Promise {
+		count++
+		d := NewPromise()
+		go d.SucceedWithResult(count)
+		return d
+	}, func(result interface{}, err error) bool {
+		return result.(int) == 3
+	})
+
+	assert.Equal(t, 3, p.Wait(make(chan Controller, 1)).(Controller).Result())
+}
+
+func TestLoopUntilMax(t *testing.T) {
+	p := LoopUntilMax(3, func() Promise {
+		return NewPromise().Fail(fmt.Errorf("failed"))
+	}, func(result interface{}, err error) bool {
+		return err == nil
+	})
+
+	assert.Equal(t, ErrMaxIterationsExceeded, p.(Controller).Error())
+}
+
+func TestSucceedOnFirst(t *testing.T) {
+	p1 := NewPromise()
+	p2 := NewPromise()
+
+	p := SucceedOnFirst(p1, p2)
+
+	p1.Fail(fmt.Errorf("failed"))
+	assert.True(t, p.(Controller).IsPending())
+
+	// nil is a valid result
+	p2.SucceedWithResult(nil)
+	assert.True(t, p.(Controller).IsSuccess())
+	assert.Nil(t, p.(Controller).Result())
+
+	err1 := fmt.Errorf("failed 1")
+	err2 := fmt.Errorf("failed 2")
+
+	p = SucceedOnFirst(NewPromise().Fail(err1), NewPromise().Fail(err2))
+	assert.ErrorIs(t, p.(Controller).Error(), err1)
+	assert.ErrorIs(t, p.(Controller).Error(), err2)
+
+	p = SucceedOnFirst()
+	assert.Equal(t, ErrNotEnoughPromises, p.(Controller).Error())
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "Promise[Pending]", NewPromise().String())
+	assert.Equal(t, "Promise[Succeeded: 42]", NewPromise().SucceedWithResult(42).String())
+	assert.Equal(t, "Promise[Failed: failed]", NewPromise().Fail(fmt.Errorf("failed")).String())
+	assert.Equal(t, "Promise[Canceled]", NewPromise().Cancel().String())
+
+	long := NewPromise().SucceedWithResult(fmt.Sprintf("%0100d", 0)).String()
+	assert.Equal(t, len("Promise[Succeeded: ]")+64, len(long))
+
+	assert.Equal(t, "Promise[Pending]", fmt.Sprint(NewPromise()))
+}
+
+func TestEqualResult(t *testing.T) {
+	assert.True(t, EqualResult(NewPromise().SucceedWithResult([]int{1}), NewPromise().SucceedWithResult([]int{1})))
+	assert.False(t, EqualResult(NewPromise().SucceedWithResult(1), NewPromise().SucceedWithResult(2)))
+	assert.True(t, EqualResult(NewPromise().Fail(fmt.Errorf("failed")), NewPromise().Fail(fmt.Errorf("failed"))))
+	assert.True(t, EqualResult(NewPromise().Cancel(), NewPromise().Cancel()))
+	assert.False(t, EqualResult(NewPromise().Cancel(), NewPromise().Fail(fmt.Errorf("failed"))))
+	assert.False(t, EqualResult(NewPromise(), NewPromise()))
+}
+
+func TestThenWithResultConcurrent(t *testing.T) {
+	var inflight, maxInflight int64
+
+	square := func(result interface{}) Promise {
+		p := NewPromise()
+
+		n := atomic.AddInt64(&inflight, 1)
+		for {
+			max := atomic.LoadInt64(&maxInflight)
+			if n <= max || atomic.CompareAndSwapInt64(&maxInflight, max, n) {
+				break
+			}
+		}
+
+		go func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt64(&inflight, -1)
+			p.SucceedWithResult(result.(int) * result.(int))
+		}()
+
+		return p
+	}
+
+	p := NewPromise().SucceedWithResult([]interface{}{1, 2, 3, 4, 5}).ThenWithResultConcurrent(2, square)
+
+	assert.Equal(t, []interface{}{1, 4, 9, 16, 25}, p.Wait(make(chan Controller, 1)).(Controller).Result())
+	assert.True(t, atomic.LoadInt64(&maxInflight) <= 2)
+
+	p = NewPromise().SucceedWithResult(42).ThenWithResultConcurrent(2, square)
+	assert.Equal(t, ErrResultNotSlice, p.(Controller).Error())
+}
+
+func TestCheckpoint(t *testing.T) {
+	var checkpoints, global []string
+
+	GlobalCheckpointHook(func(name string, p Controller) {
+		global = append(global, name)
+	})
+	defer GlobalCheckpointHook(nil)
+
+	audit := func(name string, p Controller) {
+		checkpoints = append(checkpoints, fmt.Sprintf("%s: %v", name, p.Result()))
+	}
+
+	p := NewPromise()
+
+	p.Checkpoint("fetched", audit).ThenWithResult(func(result interface{}) Promise {
+		return NewPromise().SucceedWithResult(result.(int) * 2)
+	}).Checkpoint("doubled", audit)
+
+	p.SucceedWithResult(21)
+
+	assert.Equal(t, []string{"fetched: 21", "doubled: 42"}, checkpoints)
+	assert.Equal(t, []string{"fetched", "doubled"}, global)
+}
+
+// inlineExecutor counts and runs the functions it is given
+type inlineExecutor struct {
+	executed int64
+}
+
+func (e *inlineExecutor) Execute(fn func()) {
+	atomic.AddInt64(&e.executed, 1)
+	fn()
+}
+
+func TestNewPromiseWithOptions(t *testing.T) {
+	executor := &inlineExecutor{}
+
+	p := NewPromiseWithOptions(
+		WithID("request-1"),
+		WithExecutor(executor),
+		WithHandlerOrder(LIFO))
+
+	var order []int
+	p.Always(func(Controller) { order = append(order, 1) })
+	p.Always(func(Controller) { order = append(order, 2) })
+
+	p.Succeed()
+
+	assert.Equal(t, "request-1", CorrelationID(p))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&executor.executed))
+	assert.Equal(t, []int{2, 1}, order)
+
+	p = NewPromiseWithOptions(WithTimeout(10 * time.Millisecond))
+	assert.Equal(t, ErrPromiseTimeout, p.Wait(make(chan Controller, 1)).(Controller).Error())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p = NewPromiseWithOptions(WithCancelOnContext(ctx), WithDeadline(time.Now().Add(time.Minute)))
+	cancel()
+	assert.True(t, p.Wait(make(chan Controller, 1)).(Controller).IsCanceled())
+}
+
+func TestNewPromiseWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := NewPromiseWithContext(ctx)
+	assert.True(t, p.IsPending())
+
+	cancel()
+	assert.True(t, p.Wait(make(chan Controller, 1)).(Controller).IsCanceled())
+
+	// already done
+	p = NewPromiseWithContext(ctx)
+	assert.True(t, p.IsCanceled())
+
+	// delivered normally
+	p = NewPromiseWithContext(context.Background()).SucceedWithResult(42)
+	assert.Equal(t, 42, p.Result())
+}
+
+func TestWaitContext(t *testing.T) {
+	p := NewPromise()
+
+	go p.SucceedWithResult(42)
+
+	p2, err := p.WaitContext(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 42, p2.(Controller).Result())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	p2, err = NewPromise().WaitContext(ctx)
+	assert.Nil(t, p2)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// a delivered promise wins over a done ctx
+	canceled, cancel2 := context.WithCancel(context.Background())
+	cancel2()
+
+	p = NewPromise().SucceedWithResult(42)
+
+	for i := 0; i < 100; i++ {
+		p2, err = p.WaitContext(canceled)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, p2.(Controller).Result())
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	p := NewPromise()
+
+	p2, ok := p.WaitTimeout(0)
+	assert.False(t, ok)
+	assert.Nil(t, p2)
+
+	p2, ok = p.WaitTimeout(10 * time.Millisecond)
+	assert.False(t, ok)
+	assert.Nil(t, p2)
+
+	p.SucceedWithResult(42)
+
+	p2, ok = p.WaitTimeout(0)
+	assert.True(t, ok)
+	assert.Equal(t, 42, p2.(Controller).Result())
+
+	p2, ok = p.WaitTimeout(time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 42, p2.(Controller).Result())
+}
+
+func TestTimeout(t *testing.T) {
+	p := NewPromise()
+	timed := p.Timeout(10 * time.Millisecond)
+
+	assert.Equal(t, ErrPromiseTimeout, timed.Wait(make(chan Controller, 1)).(Controller).Error())
+	assert.True(t, p.IsPending())
+
+	p = NewPromise()
+	timed = p.Timeout(time.Minute)
+
+	p.Cancel()
+	assert.True(t, timed.(Controller).IsCanceled())
+
+	timed = NewPromise().SucceedWithResult(42).Timeout(time.Minute)
+	assert.Equal(t, 42, timed.(Controller).Result())
+}
+
+func TestDeadline(t *testing.T) {
+	p := NewPromise().Deadline(time.Now().Add(-time.Second))
+	assert.Equal(t, ErrPromiseTimeout, p.(Controller).Error())
+
+	p = NewPromise().Deadline(time.Now().Add(10 * time.Millisecond))
+	assert.Equal(t, ErrPromiseTimeout, p.Wait(make(chan Controller, 1)).(Controller).Error())
+
+	p = NewPromise().SucceedWithResult(42).Deadline(time.Now().Add(time.Minute))
+	assert.Equal(t, 42, p.(Controller).Result())
+}
+
+func TestSucceedAfter(t *testing.T) {
+	p := SucceedAfter(10*time.Millisecond, 42)
+
+	assert.True(t, p.IsPending())
+	assert.Equal(t, 42, p.Wait(make(chan Controller, 1)).(Controller).Result())
+
+	// canceled before the timer fires
+	p = SucceedAfter(10*time.Millisecond, 42).Cancel()
+	time.Sleep(20 * time.Millisecond)
+
+	assert.True(t, p.IsCanceled())
+}
+
+func TestSucceedAfterUnreferenced(t *testing.T) {
+	done := make(chan interface{}, 1)
+
+	// only the handler and the chained promise are kept
+	SucceedAfter(20*time.Millisecond, 42).Success(func(result interface{}) {
+		done <- result
+	})
+
+	p := SucceedAfter(20*time.Millisecond, 21).ThenWithResult(func(result interface{}) Promise {
+		return NewPromise().SucceedWithResult(result.(int) * 2)
+	})
+
+	runtime.GC()
+
+	select {
+	case result := <-done:
+		assert.Equal(t, 42, result)
+	case <-time.After(time.Second):
+		t.Fatal("SucceedAfter was not delivered")
+	}
+
+	assert.Equal(t, 42, p.Wait(make(chan Controller, 1)).(Controller).Result())
+}
+
+func TestFailAfter(t *testing.T) {
+	err := fmt.Errorf("failed")
+
+	p := FailAfter(10*time.Millisecond, err)
+
+	assert.True(t, p.IsPending())
+	assert.Equal(t, err, p.Wait(make(chan Controller, 1)).(Controller).Error())
+
+	// delivered before the timer fires
+	p = FailAfter(10*time.Millisecond, err).SucceedWithResult(42)
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Equal(t, 42, p.Result())
+}
+
+func TestCancelAfter(t *testing.T) {
+	var onCanceled int64
+
+	p := NewPromise()
+
+	p.CancelAfter(10 * time.Millisecond).Canceled(func() {
+		atomic.AddInt64(&onCanceled, 1)
+	})
+
+	assert.True(t, p.Wait(make(chan Controller, 1)).(Controller).IsCanceled())
+	assert.Equal(t, int64(1), atomic.LoadInt64(&onCanceled))
+
+	p = NewPromise()
+	p.CancelAfter(10 * time.Millisecond)
+	p.SucceedWithResult(42)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 42, p.Result())
+}
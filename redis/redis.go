@@ -0,0 +1,120 @@
+// Package redis wraps go-redis commands as promises
+//
+//	Notes
+//		Each command runs in its own goroutine using ctx, so the command
+//		respects cancellation of ctx. Canceling the returned promise also
+//		cancels the command
+//
+package redis
+
+import (
+	"context"
+	"time"
+
+	promise "github.com/gotomgo/go-promises"
+	stdredis "github.com/redis/go-redis/v9"
+)
+
+// GetPromise runs client.Get asynchronously
+//
+//	Notes
+//		The promise succeeds with the string value of key, or fails with
+//		redis.Nil if key does not exist
+//
+func GetPromise(client *stdredis.Client, ctx context.Context, key string) promise.Promise {
+	return run(ctx, func(ctx context.Context) (interface{}, error) {
+		return client.Get(ctx, key).Result()
+	})
+}
+
+// SetPromise runs client.Set asynchronously
+//
+//	Notes
+//		The promise succeeds with the status reply ("OK"). An expiration of
+//		0 means the key does not expire
+//
+func SetPromise(client *stdredis.Client, ctx context.Context, key string, value interface{}, expiration time.Duration) promise.Promise {
+	return run(ctx, func(ctx context.Context) (interface{}, error) {
+		return client.Set(ctx, key, value, expiration).Result()
+	})
+}
+
+// DelPromise runs client.Del asynchronously
+//
+//	Notes
+//		The promise succeeds with the number of keys that were removed
+//
+func DelPromise(client *stdredis.Client, ctx context.Context, keys ...string) promise.Promise {
+	return run(ctx, func(ctx context.Context) (interface{}, error) {
+		return client.Del(ctx, keys...).Result()
+	})
+}
+
+// PublishPromise runs client.Publish asynchronously
+//
+//	Notes
+//		The promise succeeds with the number of subscribers that received
+//		the message
+//
+func PublishPromise(client *stdredis.Client, ctx context.Context, channel string, message interface{}) promise.Promise {
+	return run(ctx, func(ctx context.Context) (interface{}, error) {
+		return client.Publish(ctx, channel, message).Result()
+	})
+}
+
+// WaitForKeyPromise polls every interval until key exists
+//
+//	Notes
+//		The promise succeeds with a value of true once key exists, or fails
+//		with the error from client.Exists, or the error of ctx if ctx is
+//		done before key exists
+//
+func WaitForKeyPromise(client *stdredis.Client, ctx context.Context, key string, interval time.Duration) promise.Promise {
+	return waitFor(ctx, interval, func(ctx context.Context) (int64, error) {
+		return client.Exists(ctx, key).Result()
+	})
+}
+
+// waitFor polls exists every interval until it returns a count > 0
+func waitFor(ctx context.Context, interval time.Duration, exists func(context.Context) (int64, error)) promise.Promise {
+	return promise.ProbeWithResult(interval, func() (interface{}, bool, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		count, err := exists(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return true, count > 0, nil
+	})
+}
+
+// run invokes command asynchronously with a context that is canceled when
+// the returned promise is canceled
+func run(ctx context.Context, command func(context.Context) (interface{}, error)) promise.Promise {
+	p := promise.NewPromise()
+
+	ctx, cancel := context.WithCancel(ctx)
+	p.Canceled(promise.CanceledHandler(cancel))
+
+	go func() {
+		defer cancel()
+
+		result, err := command(ctx)
+
+		// the promise was canceled while the command was in-flight?
+		if p.IsCanceled() {
+			return
+		}
+
+		if err != nil {
+			p.Fail(err)
+		} else {
+			p.SucceedWithResult(result)
+		}
+	}()
+
+	return p
+}
@@ -0,0 +1,159 @@
+package promise
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// BackoffPolicy determines the delay before retrying a failed attempt, and
+// when to stop retrying
+type BackoffPolicy interface {
+	// NextDelay returns the delay before the next attempt, given the number
+	// of the attempt that failed (starting at 1) and its error
+	//
+	//	Notes
+	//		Returns false to stop retrying
+	//
+	NextDelay(attempt int, err error) (time.Duration, bool)
+}
+
+// constantBackoff retries with the same delay
+type constantBackoff struct {
+	delay time.Duration
+	max   int
+}
+
+// exponentialBackoff retries with a delay that grows by a multiplier
+type exponentialBackoff struct {
+	initial    time.Duration
+	max        time.Duration
+	multiplier float64
+	jitter     bool
+}
+
+// linearBackoff retries with a delay that grows by an increment
+type linearBackoff struct {
+	initial   time.Duration
+	increment time.Duration
+	max       int
+}
+
+// ConstantBackoff creates a BackoffPolicy that retries at most max times,
+// with a delay of d before each retry
+func ConstantBackoff(d time.Duration, max int) BackoffPolicy {
+	return &constantBackoff{delay: d, max: max}
+}
+
+// ExponentialBackoff creates a BackoffPolicy with a delay of initial before
+// the first retry, and the delay multiplied by multiplier for each
+// subsequent retry, up to a delay of max
+//
+//	Notes
+//		The policy does not limit the number of retries
+//
+//		If jitter is true, each delay is randomized between half of the
+//		delay and the full delay, so that clients retrying at the same time
+//		do not remain in lock step
+//
+func ExponentialBackoff(initial, max time.Duration, multiplier float64, jitter bool) BackoffPolicy {
+	return &exponentialBackoff{
+		initial:    initial,
+		max:        max,
+		multiplier: multiplier,
+		jitter:     jitter,
+	}
+}
+
+// LinearBackoff creates a BackoffPolicy that retries at most max times,
+// with a delay of initial before the first retry, and the delay increased
+// by increment for each subsequent retry
+func LinearBackoff(initial, increment time.Duration, max int) BackoffPolicy {
+	return &linearBackoff{initial: initial, increment: increment, max: max}
+}
+
+// NextDelay returns the constant delay until max retries have been made
+func (b *constantBackoff) NextDelay(attempt int, err error) (time.Duration, bool) {
+	return b.delay, attempt <= b.max
+}
+
+// NextDelay returns the exponential delay for the attempt
+func (b *exponentialBackoff) NextDelay(attempt int, err error) (time.Duration, bool) {
+	delay := float64(b.initial) * math.Pow(b.multiplier, float64(attempt-1))
+
+	if delay > float64(b.max) {
+		delay = float64(b.max)
+	}
+
+	if b.jitter && delay >= 2 {
+		half := int64(delay / 2)
+		delay = float64(half + rand.Int63n(half))
+	}
+
+	return time.Duration(delay), true
+}
+
+// NextDelay returns the linear delay for the attempt, until max retries
+// have been made
+func (b *linearBackoff) NextDelay(attempt int, err error) (time.Duration, bool) {
+	return b.initial + b.increment*time.Duration(attempt-1), attempt <= b.max
+}
+
+// NewRetryWithPolicy creates a promise that is delivered with the promise
+// from factory, retrying failed attempts as directed by policy
+//
+//	Notes
+//		If the policy stops retrying, the returned promise fails with the
+//		error of the last attempt
+//
+//		A canceled attempt is not retried, and the returned promise is
+//		canceled. Likewise, if the returned promise is delivered (for
+//		example, canceled by the caller) no further attempts are made
+//
+func NewRetryWithPolicy(policy BackoffPolicy, factory Factory) Promise {
+	result := newPromise()
+
+	var attempt func(n int)
+	attempt = func(n int) {
+		if result.IsDelivered() {
+			return
+		}
+
+		factory().Always(func(p Controller) {
+			if !p.IsFailed() || p.IsCanceled() {
+				result.tryDeliver(p.RawResult())
+				return
+			}
+
+			delay, ok := policy.NextDelay(n, p.Error())
+			if !ok {
+				result.tryDeliver(p.Error())
+				return
+			}
+
+			time.AfterFunc(delay, func() {
+				attempt(n + 1)
+			})
+		})
+	}
+
+	attempt(1)
+
+	return result
+}
+
+// limitedBackoff limits the number of attempts of a BackoffPolicy
+type limitedBackoff struct {
+	BackoffPolicy
+	maxAttempts int
+}
+
+// NextDelay returns the delay of the policy, until maxAttempts attempts
+// have been made
+func (b *limitedBackoff) NextDelay(attempt int, err error) (time.Duration, bool) {
+	if attempt >= b.maxAttempts {
+		return 0, false
+	}
+
+	return b.BackoffPolicy.NextDelay(attempt, err)
+}
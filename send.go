@@ -0,0 +1,69 @@
+package promise
+
+import "sync"
+
+// MultiSend delivers result to each of the controllers, in order
+//
+//	Notes
+//		Each controller is delivered via Deliver, so an error result fails
+//		the controllers. A controller that is already delivered is logged
+//		and skipped
+//
+//		The handlers of each controller are invoked synchronously by its
+//		delivery, so the controllers are delivered one after another. Use
+//		MultiSendConcurrent to deliver them concurrently
+//
+func MultiSend(result interface{}, controllers ...Controller) {
+	for _, c := range controllers {
+		c.Deliver(result)
+	}
+}
+
+// MultiSendConcurrent delivers result to each of the controllers, using a
+// goroutine for each delivery
+//
+//	Notes
+//		MultiSendConcurrent returns once all of the controllers have been
+//		delivered (and their handlers invoked). See MultiSend
+//
+func MultiSendConcurrent(result interface{}, controllers ...Controller) {
+	var wg sync.WaitGroup
+
+	wg.Add(len(controllers))
+
+	for _, c := range controllers {
+		go func(c Controller) {
+			defer wg.Done()
+			c.Deliver(result)
+		}(c)
+	}
+
+	wg.Wait()
+}
+
+// MultiSucceed delivers each of the controllers with a value of true
+func MultiSucceed(controllers ...Controller) {
+	MultiSend(true, controllers...)
+}
+
+// MultiFail fails each of the controllers with err
+func MultiFail(err error, controllers ...Controller) {
+	MultiSend(err, controllers...)
+}
+
+// ResolveAll creates a Controller, already succeeded, for each of the
+// results
+//
+//	Notes
+//		The controllers are created via NewPromiseSlice. As with Deliver, an
+//		error result creates a failed Controller
+//
+func ResolveAll(results []interface{}) []Controller {
+	promises := NewPromiseSlice(len(results))
+
+	for i, result := range results {
+		promises[i].Deliver(result)
+	}
+
+	return promises
+}
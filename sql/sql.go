@@ -0,0 +1,129 @@
+// Package sql bridges database/sql queries to promises
+//
+//	Notes
+//		Each query runs in its own goroutine using the context aware
+//		database/sql methods, so the query respects cancellation of ctx.
+//		Canceling the returned promise also cancels the query
+//
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+
+	promise "github.com/gotomgo/go-promises"
+)
+
+// QueryPromise runs db.QueryContext asynchronously
+//
+//	Notes
+//		The promise succeeds with the *sql.Rows from the query, and the
+//		caller is responsible for closing the rows. If the promise is
+//		canceled while the query runs, the rows are closed instead
+//
+func QueryPromise(db *stdsql.DB, ctx context.Context, query string, args ...interface{}) promise.Promise {
+	p := promise.NewPromise()
+
+	ctx, cancel := cancelWithPromise(ctx, p)
+
+	go func() {
+		rows, err := db.QueryContext(ctx, query, args...)
+
+		if p.IsCanceled() {
+			if err == nil {
+				rows.Close()
+			}
+
+			cancel()
+			return
+		}
+
+		if err != nil {
+			cancel()
+			p.Fail(err)
+			return
+		}
+
+		// the rows can only be read while ctx is active, so ctx is not
+		// canceled here (see cancelWithPromise)
+		p.SucceedWithResult(rows)
+	}()
+
+	return p
+}
+
+// ExecPromise runs db.ExecContext asynchronously
+//
+//	Notes
+//		The promise succeeds with the sql.Result from the statement
+//
+func ExecPromise(db *stdsql.DB, ctx context.Context, query string, args ...interface{}) promise.Promise {
+	p := promise.NewPromise()
+
+	ctx, cancel := cancelWithPromise(ctx, p)
+
+	go func() {
+		defer cancel()
+
+		result, err := db.ExecContext(ctx, query, args...)
+
+		if p.IsCanceled() {
+			return
+		}
+
+		if err != nil {
+			p.Fail(err)
+		} else {
+			p.SucceedWithResult(result)
+		}
+	}()
+
+	return p
+}
+
+// QueryRowPromise runs db.QueryRowContext asynchronously and scans the
+// row into a T using scan
+//
+//	Notes
+//		The promise fails with the error from scan, which is sql.ErrNoRows
+//		if the query did not return a row
+//
+func QueryRowPromise[T any](db *stdsql.DB, scan func(*stdsql.Row) (T, error), ctx context.Context, query string, args ...interface{}) promise.TypedPromise[T] {
+	p := promise.NewTypedPromise[T]()
+
+	ctx, cancel := cancelWithPromise(ctx, p)
+
+	go func() {
+		defer cancel()
+
+		value, err := scan(db.QueryRowContext(ctx, query, args...))
+
+		if p.IsCanceled() {
+			return
+		}
+
+		if err != nil {
+			p.Fail(err)
+		} else {
+			p.SucceedWithResult(value)
+		}
+	}()
+
+	return p
+}
+
+// cancelWithPromise returns a context derived from ctx that is canceled
+// when p is canceled
+//
+//	Notes
+//		QueryPromise does not cancel the context when the query succeeds,
+//		as *sql.Rows can only be read while the context of the query is
+//		active. The context is then released when ctx is done
+//
+func cancelWithPromise(ctx context.Context, p promise.Promise) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	p.Canceled(promise.CanceledHandler(cancel))
+
+	return ctx, cancel
+}
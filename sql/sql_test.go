@@ -0,0 +1,195 @@
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	promise "github.com/gotomgo/go-promises"
+	"github.com/stretchr/testify/assert"
+)
+
+var errQuery = fmt.Errorf("Testing query failure")
+
+// fakeBlock, if set, is used by the "block" query to signal that it has
+// started, and to wait until it is released
+var fakeBlock struct {
+	started chan struct{}
+	release chan struct{}
+	closed  chan struct{}
+}
+
+// fakeDriver is a database/sql driver where every query returns the rows
+// 1 and 2, except for "fail" and "block"
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{query: query}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	if s.query == "fail" {
+		return nil, errQuery
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	switch s.query {
+	case "fail":
+		return nil, errQuery
+	case "block":
+		close(fakeBlock.started)
+		<-fakeBlock.release
+
+		return &fakeRows{values: []int64{1}, closed: fakeBlock.closed}, nil
+	}
+
+	return &fakeRows{values: []int64{1, 2}}, nil
+}
+
+type fakeRows struct {
+	values []int64
+	closed chan struct{}
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"n"}
+}
+
+func (r *fakeRows) Close() error {
+	if r.closed != nil {
+		close(r.closed)
+		r.closed = nil
+	}
+
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	dest[0], r.values = r.values[0], r.values[1:]
+
+	return nil
+}
+
+func init() {
+	stdsql.Register("promise-fake", fakeDriver{})
+}
+
+func openDB(t *testing.T) *stdsql.DB {
+	db, err := stdsql.Open("promise-fake", "")
+	assert.Nil(t, err)
+
+	return db
+}
+
+func TestQueryPromise(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	p := QueryPromise(db, context.Background(), "select n").Wait(make(chan promise.Controller, 1)).(promise.Controller)
+
+	rows := p.Result().(*stdsql.Rows)
+	defer rows.Close()
+
+	var values []int64
+	for rows.Next() {
+		var n int64
+		assert.Nil(t, rows.Scan(&n))
+
+		values = append(values, n)
+	}
+
+	assert.Equal(t, []int64{1, 2}, values)
+
+	p = QueryPromise(db, context.Background(), "fail").Wait(make(chan promise.Controller, 1)).(promise.Controller)
+	assert.Equal(t, errQuery, p.Error())
+}
+
+func TestQueryPromiseCanceled(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	fakeBlock.started = make(chan struct{})
+	fakeBlock.release = make(chan struct{})
+	fakeBlock.closed = make(chan struct{})
+
+	p := QueryPromise(db, context.Background(), "block").(promise.Controller)
+
+	<-fakeBlock.started
+	p.Cancel()
+	close(fakeBlock.release)
+
+	select {
+	case <-fakeBlock.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the rows of a canceled query were not closed")
+	}
+
+	assert.True(t, p.IsCanceled())
+}
+
+func TestExecPromise(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	p := ExecPromise(db, context.Background(), "update").Wait(make(chan promise.Controller, 1)).(promise.Controller)
+
+	affected, err := p.Result().(stdsql.Result).RowsAffected()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), affected)
+}
+
+func TestQueryRowPromise(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	scan := func(row *stdsql.Row) (int64, error) {
+		var n int64
+		err := row.Scan(&n)
+
+		return n, err
+	}
+
+	p := QueryRowPromise(db, scan, context.Background(), "select n")
+	p.Wait(make(chan promise.Controller, 1))
+
+	value, ok := p.TypedResult()
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), value)
+}
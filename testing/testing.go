@@ -0,0 +1,84 @@
+// Package testing provides assertion helpers for tests of promise-based code
+//
+//	Notes
+//		The package is named testing to read naturally at the call site
+//		(promisetesting.Eventually), so the standard library testing package
+//		is imported as stdtesting
+//
+package testing
+
+import (
+	"reflect"
+	stdtesting "testing"
+	"time"
+
+	promise "github.com/gotomgo/go-promises"
+)
+
+// Eventually asserts that p succeeds within timeout with a result equal
+// (via reflect.DeepEqual) to expected
+//
+//	Notes
+//		The test fails (via t.Errorf) if p fails, is canceled, does not
+//		deliver within timeout, or delivers an unexpected result. Returns
+//		true if the assertion succeeded
+//
+func Eventually(t *stdtesting.T, p promise.Promise, expected interface{}, timeout time.Duration) bool {
+	t.Helper()
+
+	result, ok := await(t, p, timeout)
+	if !ok {
+		return false
+	}
+
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("promise result: expected %#v, got %#v", expected, result)
+		return false
+	}
+
+	return true
+}
+
+// EventuallyMatches asserts that p succeeds within timeout with a result
+// accepted by matcher
+//
+//	Notes
+//		See Eventually
+//
+func EventuallyMatches(t *stdtesting.T, p promise.Promise, matcher func(interface{}) bool, timeout time.Duration) bool {
+	t.Helper()
+
+	result, ok := await(t, p, timeout)
+	if !ok {
+		return false
+	}
+
+	if !matcher(result) {
+		t.Errorf("promise result %#v does not match", result)
+		return false
+	}
+
+	return true
+}
+
+// await waits up to timeout for p to succeed, and returns the result
+func await(t *stdtesting.T, p promise.Promise, timeout time.Duration) (interface{}, bool) {
+	t.Helper()
+
+	// buffered so that the notification does not block after a timeout
+	waitChan := make(chan promise.Controller, 1)
+	p.Signal(waitChan)
+
+	select {
+	case p2 := <-waitChan:
+		if err := p2.Error(); err != nil {
+			t.Errorf("promise failed: %s", err)
+			return nil, false
+		}
+
+		return p2.Result(), true
+	case <-time.After(timeout):
+		t.Errorf("promise was not delivered within %s", timeout)
+		return nil, false
+	}
+}
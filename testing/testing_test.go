@@ -0,0 +1,24 @@
+package testing
+
+import (
+	stdtesting "testing"
+	"time"
+
+	promise "github.com/gotomgo/go-promises"
+)
+
+func TestEventually(t *stdtesting.T) {
+	p := promise.NewPromise()
+
+	go p.SucceedWithResult([]int{1, 2})
+
+	Eventually(t, p, []int{1, 2}, time.Second)
+}
+
+func TestEventuallyMatches(t *stdtesting.T) {
+	p := promise.NewPromise().SucceedWithResult(42)
+
+	EventuallyMatches(t, p, func(result interface{}) bool {
+		return result.(int) > 40
+	}, time.Second)
+}
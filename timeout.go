@@ -0,0 +1,227 @@
+package promise
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// defaultTimeout is the default timeout (in nanoseconds) applied by
+// NewPromise, or 0 for no default timeout
+var defaultTimeout int64
+
+// SetDefaultTimeout sets a timeout that is applied to every promise
+// subsequently created by NewPromise
+//
+//	Notes
+//		A promise created with a default timeout fails with
+//		ErrPromiseTimeout if it is not delivered within d
+//
+//		The default timeout applies to the promises created by the public
+//		constructors (NewPromise, NewPromiseWithContext, FromFunc, etc.),
+//		unless an explicit timeout or deadline is given. It does not apply
+//		to the promises derived from other promises, such as the result of
+//		Thenf, Timeout, Delay or All
+//
+//		SetDefaultTimeout(0) disables the default timeout, which is the
+//		initial behavior
+//
+func SetDefaultTimeout(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	atomic.StoreInt64(&defaultTimeout, int64(d))
+}
+
+// DefaultTimeout returns the timeout set via SetDefaultTimeout, or 0 if
+// there is no default timeout
+func DefaultTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&defaultTimeout))
+}
+
+// NewPromiseWithDeadline creates a promise that fails with ErrPromiseTimeout
+// if it has not been delivered by t
+//
+//	Notes
+//		The deadline timer is started before the promise is returned, so
+//		there is no window in which the promise exists without its deadline
+//
+//		If t is not in the future, the promise is returned already failed
+//
+func NewPromiseWithDeadline(t time.Time) Controller {
+	p := newPromise()
+
+	p.expireAfter(time.Until(t))
+
+	return p
+}
+
+// expireAfter fails the promise with ErrPromiseTimeout if it is still
+// pending after d
+//
+//	Notes
+//		The timer is stopped when the promise is delivered so that it does
+//		not outlive the promise
+//
+func (p *promise) expireAfter(d time.Duration) {
+	p.deliverIfPendingAfter(d, ErrPromiseTimeout)
+}
+
+// deliverIfPendingAfter delivers the promise with result if it is still
+// pending after d
+//
+//	Notes
+//		If d <= 0, the promise is delivered immediately (if pending). The
+//		timer is stopped when the promise is delivered so that it does not
+//		outlive the promise
+//
+func (p *promise) deliverIfPendingAfter(d time.Duration, result interface{}) {
+	if d <= 0 {
+		p.tryDeliver(result)
+		return
+	}
+
+	timer := time.AfterFunc(d, func() {
+		p.tryDeliver(result)
+	})
+
+	p.Always(func(Controller) {
+		timer.Stop()
+	})
+}
+
+// withTimeout returns a promise that is delivered with the result of p, or
+// fails with ErrPromiseTimeout if p is not delivered within d
+//
+//	Notes
+//		p is not canceled on timeout, and its later delivery is ignored
+//
+func withTimeout(p Promise, d time.Duration) Promise {
+	result := newPromise()
+
+	result.expireAfter(d)
+
+	p.Always(func(p2 Controller) {
+		result.tryDeliver(p2.RawResult())
+	})
+
+	return result
+}
+
+// DeliverTimeout creates a promise that is delivered with result after d
+//
+//	Notes
+//		If result is an error the promise fails, otherwise it succeeds (see
+//		Deliver). If the promise is delivered before d (for example, it is
+//		canceled), the scheduled delivery is stopped
+//
+//		Raced against another promise, DeliverTimeout(d, ErrPromiseTimeout)
+//		provides a result-or-timeout pattern
+//
+func DeliverTimeout(d time.Duration, result interface{}) Controller {
+	p := newPromise()
+
+	timer := time.AfterFunc(d, func() {
+		p.tryDeliver(result)
+	})
+
+	p.Always(func(Controller) {
+		timer.Stop()
+	})
+
+	return p
+}
+
+// SucceedAfter creates a promise that succeeds with result after d
+//
+//	Notes
+//		If the promise is delivered before d (for example, it is canceled),
+//		the scheduled success is skipped
+//
+func SucceedAfter(d time.Duration, result interface{}) Controller {
+	return deliverAfter(d, result)
+}
+
+// FailAfter creates a promise that fails with err after d
+//
+//	Notes
+//		If the promise is delivered before d, the scheduled failure is
+//		skipped (without logging a double delivery). See SucceedAfter
+//
+func FailAfter(d time.Duration, err error) Controller {
+	return deliverAfter(d, err)
+}
+
+// deliverAfter creates a promise that is delivered with result after d
+//
+//	Notes
+//		The timer holds a reference to the promise, so the promise is
+//		delivered even if the caller only keeps a chained promise or its
+//		handlers. The timer is stopped when the promise is delivered
+//
+func deliverAfter(d time.Duration, result interface{}) *promise {
+	p := newPromise()
+
+	p.deliverIfPendingAfter(d, result)
+
+	return p
+}
+
+// Delay returns a promise that is delivered with the result of p, d after
+// p is delivered
+//
+//	Notes
+//		If the returned promise is delivered first (for example, it is
+//		canceled), the delayed delivery is ignored
+//
+func Delay(d time.Duration, p Promise) Promise {
+	result := newPromise()
+
+	p.Always(func(p2 Controller) {
+		time.AfterFunc(d, func() {
+			result.tryDeliver(p2.RawResult())
+		})
+	})
+
+	return result
+}
+
+// Jitter returns a promise that is delivered with the result of p, after a
+// random delay in [min, max) once p is delivered
+//
+//	Notes
+//		The delay is uniformly distributed, which spreads out the delivery
+//		of many promises (e.g. retries) that would otherwise be notified at
+//		the same time. If max <= min, the delay is min
+//
+func Jitter(min, max time.Duration, p Promise) Promise {
+	d := min
+	if max > min {
+		d += time.Duration(rand.Int63n(int64(max - min)))
+	}
+
+	return Delay(d, p)
+}
+
+// Timeout returns a promise that is delivered with the result of this
+// promise, or fails with ErrPromiseTimeout if this promise is not delivered
+// within d
+func (p *promise) Timeout(d time.Duration) Promise {
+	return withTimeout(p, d)
+}
+
+// Deadline returns a promise that is delivered with the result of this
+// promise, or fails with ErrPromiseTimeout if this promise is not delivered
+// by t
+func (p *promise) Deadline(t time.Time) Promise {
+	return withTimeout(p, time.Until(t))
+}
+
+// CancelAfter cancels this promise if it is still pending after d, and
+// returns this promise
+func (p *promise) CancelAfter(d time.Duration) Promise {
+	p.deliverIfPendingAfter(d, ErrPromiseCanceled)
+
+	return p
+}
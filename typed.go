@@ -0,0 +1,182 @@
+package promise
+
+import (
+	"context"
+	"fmt"
+)
+
+// TypedPromise is a Promise whose successful result is of type T
+type TypedPromise[T any] interface {
+	Promise
+
+	// TypedResult returns the successful result of the delivery as a T
+	//
+	//	Notes
+	//		Returns false if the promise has not been successfully delivered,
+	//		or the result is not a T. A nil result is returned as the zero
+	//		value of T
+	//
+	TypedResult() (T, bool)
+}
+
+// TypedController is a Controller whose successful result is of type T
+type TypedController[T any] interface {
+	Controller
+
+	// TypedResult returns the successful result of the delivery as a T
+	//
+	//	Notes
+	//		See TypedPromise
+	//
+	TypedResult() (T, bool)
+}
+
+// typedPromise implements TypedController by wrapping a Controller
+type typedPromise[T any] struct {
+	Controller
+}
+
+// NewTypedPromise creates a TypedController for a new promise
+func NewTypedPromise[T any]() TypedController[T] {
+	return Typed[T](NewPromise())
+}
+
+// Typed returns a TypedController view of a Controller
+//
+//	Notes
+//		The view shares the state of p, so delivering either one delivers
+//		both
+//
+func Typed[T any](p Controller) TypedController[T] {
+	if typed, ok := p.(TypedController[T]); ok {
+		return typed
+	}
+
+	return &typedPromise[T]{Controller: p}
+}
+
+// TypedResult returns the successful result of the delivery as a T
+func (p *typedPromise[T]) TypedResult() (result T, ok bool) {
+	if !p.IsSuccess() {
+		return
+	}
+
+	// a successful nil result is the zero value of T
+	if res := p.Result(); res != nil {
+		result, ok = res.(T)
+	} else {
+		ok = true
+	}
+
+	return
+}
+
+// FromFunc creates a TypedPromise that is delivered with the result of fn,
+// which is invoked in a new goroutine
+//
+//	Notes
+//		The promise fails with the error returned by fn, or with an error
+//		for the panic if fn panics
+//
+func FromFunc[T any](fn func() (T, error)) TypedPromise[T] {
+	p := newPromise()
+
+	p.applyDefaultTimeout()
+
+	go invoke(p, fn)
+
+	return Typed[T](p)
+}
+
+// FromFuncCtx creates a TypedPromise that is delivered with the result of
+// fn, which is invoked with ctx in a new goroutine
+//
+//	Notes
+//		If ctx is done before fn returns, the promise is canceled, and the
+//		eventual result of fn is discarded
+//
+func FromFuncCtx[T any](ctx context.Context, fn func(context.Context) (T, error)) TypedPromise[T] {
+	p := newPromise()
+
+	p.applyDefaultTimeout()
+
+	stop := context.AfterFunc(ctx, func() {
+		p.tryDeliver(ErrPromiseCanceled)
+	})
+
+	p.Always(func(Controller) {
+		stop()
+	})
+
+	go invoke(p, func() (T, error) {
+		result, err := fn(ctx)
+
+		// the AfterFunc may not have run yet, so check ctx directly
+		if ctx.Err() != nil {
+			return result, ErrPromiseCanceled
+		}
+
+		return result, err
+	})
+
+	return Typed[T](p)
+}
+
+// invoke delivers p with the result of fn, recovering from a panic in fn
+func invoke[T any](p *promise, fn func() (T, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.tryDeliver(fmt.Errorf("promise func panic'd: %v", r))
+		}
+	}()
+
+	result, err := fn()
+	if err != nil {
+		p.tryDeliver(err)
+	} else {
+		p.tryDeliver(result)
+	}
+}
+
+// ResolveAllTyped creates a TypedController, already succeeded, for each of
+// the results
+func ResolveAllTyped[T any](results []T) []TypedController[T] {
+	promises := NewPromiseSlice(len(results))
+	typed := make([]TypedController[T], len(results))
+
+	for i, result := range results {
+		typed[i] = Typed[T](promises[i].SucceedWithResult(result))
+	}
+
+	return typed
+}
+
+// AtomicResult provides typed access to the result of a TypedPromise,
+// without a type assertion at each access
+//
+//	Notes
+//		The result of a promise is stored atomically when it is delivered,
+//		so an AtomicResult can be read from any goroutine at any time
+//
+type AtomicResult[T any] struct {
+	promise TypedPromise[T]
+}
+
+// NewAtomicResult creates an AtomicResult for p
+func NewAtomicResult[T any](p TypedPromise[T]) *AtomicResult[T] {
+	return &AtomicResult[T]{promise: p}
+}
+
+// Get returns the result, and false if the promise has not succeeded
+func (r *AtomicResult[T]) Get() (T, bool) {
+	return r.promise.TypedResult()
+}
+
+// GetOrDefault returns the result, or def if the promise has not succeeded
+func (r *AtomicResult[T]) GetOrDefault(def T) T {
+	if result, ok := r.promise.TypedResult(); ok {
+		return result
+	}
+
+	return def
+}
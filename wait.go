@@ -0,0 +1,72 @@
+package promise
+
+import (
+	"context"
+	"time"
+)
+
+// Unwrap blocks until p is delivered, and returns the result or the error
+//
+//	Notes
+//		Returns (result, nil) if p succeeds, or (nil, err) if p fails or is
+//		canceled
+//
+func Unwrap(p Promise) (interface{}, error) {
+	// buffered so that an already delivered promise does not block the
+	// synchronous Always notification inside Wait
+	p2 := p.Wait(make(chan Controller, 1)).(Controller)
+
+	if err := p2.Error(); err != nil {
+		return nil, err
+	}
+
+	return p2.Result(), nil
+}
+
+// WaitContext blocks until this promise is delivered, or ctx is done
+func (p *promise) WaitContext(ctx context.Context) (Promise, error) {
+	// a delivered promise is returned even if ctx is already done
+	if p.IsDelivered() {
+		return p, nil
+	}
+
+	// buffered so that the notification does not block if ctx is done first
+	waitChan := make(chan Controller, 1)
+	p.Signal(waitChan)
+
+	select {
+	case p2 := <-waitChan:
+		return p2, nil
+	case <-ctx.Done():
+		// select chooses randomly when both are ready, so prefer the
+		// delivery if it has already been signaled
+		select {
+		case p2 := <-waitChan:
+			return p2, nil
+		default:
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// WaitTimeout blocks until this promise is delivered, for at most d
+func (p *promise) WaitTimeout(d time.Duration) (Promise, bool) {
+	if d <= 0 {
+		if p.IsDelivered() {
+			return p, true
+		}
+
+		return nil, false
+	}
+
+	// buffered so that the notification does not block after a timeout
+	waitChan := make(chan Controller, 1)
+	p.Signal(waitChan)
+
+	select {
+	case p2 := <-waitChan:
+		return p2, true
+	case <-time.After(d):
+		return nil, false
+	}
+}